@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/repository"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// Principal is the identity authenticated by an API key
+type Principal struct {
+	ID     string   `json:"id"`
+	Scopes []string `json:"scopes"`
+}
+
+// Claims returns the claims representing the principal, with its ID as sub claim and its scopes as space-delimited scope claim
+func (p Principal) Claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   p.ID,
+		"scope": strings.Join(p.Scopes, " "),
+	}
+}
+
+// APIKeyStore resolves the principal of an API key
+type APIKeyStore interface {
+	// Lookup returns the principal of the API key, or an UnauthorizedErr when the key is not valid
+	Lookup(ctx context.Context, key string) (Principal, error)
+}
+
+// APIKeyValidator is a TokenValidator that validates API keys against an APIKeyStore, returning the claims of their principal
+type APIKeyValidator struct {
+	Store APIKeyStore
+}
+
+// NewAPIKeyValidator creates a new APIKeyValidator with the given store
+func NewAPIKeyValidator(store APIKeyStore) *APIKeyValidator {
+	return &APIKeyValidator{Store: store}
+}
+
+// Validate looks up the API key in the store and returns the claims of its principal
+func (v *APIKeyValidator) Validate(ctx context.Context, key string) (jwt.MapClaims, error) {
+	if key == "" {
+		return nil, wrappers.NewUnauthorizedErr(errors.New("API key is not provided"))
+	}
+
+	principal, err := v.Store.Lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return principal.Claims(), nil
+}
+
+// StaticAPIKeyStore is an APIKeyStore backed by a fixed set of keys, holding only their hashes
+type StaticAPIKeyStore map[string]Principal
+
+// NewStaticAPIKeyStore creates a new StaticAPIKeyStore from the map of API keys to their principals
+func NewStaticAPIKeyStore(keys map[string]Principal) StaticAPIKeyStore {
+	store := make(StaticAPIKeyStore, len(keys))
+	for key, principal := range keys {
+		store[HashAPIKey(key)] = principal
+	}
+	return store
+}
+
+// Lookup returns the principal of the API key
+func (s StaticAPIKeyStore) Lookup(ctx context.Context, key string) (Principal, error) {
+	principal, ok := s[HashAPIKey(key)]
+	if !ok {
+		return Principal{}, wrappers.NewUnauthorizedErr(errors.New("invalid API key"))
+	}
+	return principal, nil
+}
+
+// APIKey is the entity stored by the RepositoryAPIKeyStore, identified by the hash of the key
+type APIKey struct {
+	ID        string    `json:"id,omitempty" bson:"_id,omitempty"`
+	KeyHash   string    `json:"key_hash" bson:"key_hash"`
+	Principal string    `json:"principal" bson:"principal"`
+	Scopes    []string  `json:"scopes" bson:"scopes"`
+	Revoked   bool      `json:"revoked" bson:"revoked"`
+	ExpiresAt time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+}
+
+// RepositoryAPIKeyStore is an APIKeyStore looking up the hashed keys through a repository.Repository,
+// which must be configured with APIKey as target entity
+type RepositoryAPIKeyStore struct {
+	repo repository.Repository
+}
+
+// NewRepositoryAPIKeyStore creates a new RepositoryAPIKeyStore with the given repository
+func NewRepositoryAPIKeyStore(repo repository.Repository) *RepositoryAPIKeyStore {
+	return &RepositoryAPIKeyStore{repo: repo}
+}
+
+// Create generates a new API key for the principal and stores its hash, returning the key that must be handed to the caller
+func (s *RepositoryAPIKeyStore) Create(ctx context.Context, principal Principal, expiresAt time.Time) (string, error) {
+	key, err := GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.repo.Create(ctx, APIKey{
+		KeyHash:   HashAPIKey(key),
+		Principal: principal.ID,
+		Scopes:    principal.Scopes,
+		ExpiresAt: expiresAt.UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Lookup returns the principal of the API key when it is neither revoked nor expired
+func (s *RepositoryAPIKeyStore) Lookup(ctx context.Context, key string) (Principal, error) {
+	take := 1
+	entities, err := s.repo.Get(ctx, map[string]interface{}{"key_hash": HashAPIKey(key)}, nil, &take)
+	if err != nil {
+		if errors.Is(err, wrappers.NonExistentErr) {
+			return Principal{}, wrappers.NewUnauthorizedErr(errors.New("invalid API key"))
+		}
+		return Principal{}, err
+	}
+
+	apiKey, ok := entities[0].(*APIKey)
+	if !ok {
+		return Principal{}, fmt.Errorf("unexpected API key entity %T", entities[0])
+	}
+	if apiKey.Revoked || (!apiKey.ExpiresAt.IsZero() && time.Now().After(apiKey.ExpiresAt)) {
+		return Principal{}, wrappers.NewUnauthorizedErr(errors.New("invalid API key: revoked or expired"))
+	}
+	return Principal{ID: apiKey.Principal, Scopes: apiKey.Scopes}, nil
+}
+
+// GenerateAPIKey generates a new random API key
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashAPIKey returns the SHA-256 hash of the API key, which is what the stores keep instead of the key
+func HashAPIKey(key string) string {
+	return hashToken(key)
+}
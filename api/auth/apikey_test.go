@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAPIKeyValidator_StaticStore checks that the validator returns the claims of the principal of a known key and rejects the others
+func TestAPIKeyValidator_StaticStore(t *testing.T) {
+	store := NewStaticAPIKeyStore(map[string]Principal{"test-key": {ID: "partner", Scopes: []string{"orders:read", "orders:write"}}})
+	validator := NewAPIKeyValidator(store)
+
+	cases := []struct {
+		name        string
+		key         string
+		expectedSub string
+		expectedErr string
+	}{
+		{"Known key", "test-key", "partner", ""},
+		{"Unknown key", "other-key", "", "invalid API key"},
+		{"Missing key", "", "", "API key is not provided"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			claims, err := validator.Validate(context.Background(), tt.key)
+
+			// Assert
+			if tt.expectedErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectedSub, claims["sub"])
+				assert.Equal(t, "orders:read orders:write", claims["scope"])
+			} else {
+				assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+				assert.Equal(t, tt.expectedErr, err.Error())
+			}
+		})
+	}
+}
+
+// TestStaticAPIKeyStore_HashedKeys checks that the static store does not hold the plain keys
+func TestStaticAPIKeyStore_HashedKeys(t *testing.T) {
+	// Act
+	store := NewStaticAPIKeyStore(map[string]Principal{"test-key": {ID: "partner"}})
+
+	// Assert
+	assert.NotContains(t, store, "test-key")
+	assert.Contains(t, store, HashAPIKey("test-key"))
+}
+
+// TestRepositoryAPIKeyStore_Ok checks that a created key is looked up with its principal and scopes
+func TestRepositoryAPIKeyStore_Ok(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newMemoryRepository(APIKey{})
+	store := NewRepositoryAPIKeyStore(repo)
+	key, err := store.Create(ctx, Principal{ID: "partner", Scopes: []string{"orders:read"}}, time.Time{})
+	assert.Nil(t, err)
+
+	// Act
+	principal, err := store.Lookup(ctx, key)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Principal{ID: "partner", Scopes: []string{"orders:read"}}, principal)
+	for _, document := range repo.documents {
+		assert.Equal(t, HashAPIKey(key), document["key_hash"])
+	}
+}
+
+// TestRepositoryAPIKeyStore_Invalid checks that unknown, expired and revoked keys are rejected
+func TestRepositoryAPIKeyStore_Invalid(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newMemoryRepository(APIKey{})
+	store := NewRepositoryAPIKeyStore(repo)
+	expired, _ := store.Create(ctx, Principal{ID: "expired"}, time.Now().Add(-time.Minute))
+	repo.Create(ctx, APIKey{KeyHash: HashAPIKey("revoked-key"), Principal: "revoked", Revoked: true})
+
+	cases := []struct {
+		name        string
+		key         string
+		expectedErr string
+	}{
+		{"Unknown key", "unknown-key", "invalid API key"},
+		{"Expired key", expired, "invalid API key: revoked or expired"},
+		{"Revoked key", "revoked-key", "invalid API key: revoked or expired"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := store.Lookup(ctx, tt.key)
+
+			// Assert
+			assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+			assert.Equal(t, tt.expectedErr, err.Error())
+		})
+	}
+}
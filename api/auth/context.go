@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+)
+
+type claimsCtxKey string
+
+// ClaimsKey is the context key of the claims of the authenticated caller, set by the HTTP middlewares and the gRPC interceptors
+const ClaimsKey claimsCtxKey = "claims"
+
+// ContextWithClaims returns a copy of the context holding the claims, adding their subject to the request-scoped logger held by the context
+func ContextWithClaims(ctx context.Context, claims jwt.MapClaims) context.Context {
+	if subject, ok := claims["sub"].(string); ok && subject != "" {
+		observability.AddLogAttrs(ctx, slog.String("subject", subject))
+	}
+	return context.WithValue(ctx, ClaimsKey, claims)
+}
+
+// ClaimsFromContext returns the claims held by the context, if any
+func ClaimsFromContext(ctx context.Context) (jwt.MapClaims, bool) {
+	claims, ok := ctx.Value(ClaimsKey).(jwt.MapClaims)
+	return claims, ok
+}
+
+// Subject returns the sub claim held by the context, or an empty string when not found
+func Subject(ctx context.Context) string {
+	return StringClaim(ctx, "sub")
+}
+
+// Tenant returns the tenant claim held by the context, or an empty string when not found
+func Tenant(ctx context.Context) string {
+	return StringClaim(ctx, "tenant")
+}
+
+// Roles returns the roles claim held by the context, which can be a single role or a list of roles
+func Roles(ctx context.Context) []string {
+	claims, _ := ClaimsFromContext(ctx)
+	return claimValues(claims["roles"])
+}
+
+// Scopes returns the scopes of the space-delimited scope claim held by the context
+func Scopes(ctx context.Context) []string {
+	return strings.Fields(StringClaim(ctx, "scope"))
+}
+
+// StringClaim returns the string claim held by the context, or an empty string when not found
+func StringClaim(ctx context.Context, name string) string {
+	claims, _ := ClaimsFromContext(ctx)
+	value, _ := claims[name].(string)
+	return value
+}
+
+// DecodeClaims decodes the claims held by the context into the target struct using its json tags
+func DecodeClaims(ctx context.Context, target interface{}) error {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return errors.New("claims not found in context")
+	}
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return fmt.Errorf("failed to decode claims: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode claims: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClaimsFromContext_Ok checks that the typed getters return the claims held by the context
+func TestClaimsFromContext_Ok(t *testing.T) {
+	// Arrange
+	claims := jwt.MapClaims{"sub": "test-subject", "tenant": "test-tenant", "roles": []interface{}{"admin", "viewer"}, "scope": "read write"}
+
+	// Act
+	ctx := ContextWithClaims(context.Background(), claims)
+
+	// Assert
+	found, ok := ClaimsFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, claims, found)
+	assert.Equal(t, "test-subject", Subject(ctx))
+	assert.Equal(t, "test-tenant", Tenant(ctx))
+	assert.Equal(t, []string{"admin", "viewer"}, Roles(ctx))
+	assert.Equal(t, []string{"read", "write"}, Scopes(ctx))
+	assert.Equal(t, "", StringClaim(ctx, "missing"))
+}
+
+// TestClaimsFromContext_NotFound checks that the getters return zero values when the context does not hold claims
+func TestClaimsFromContext_NotFound(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	_, ok := ClaimsFromContext(ctx)
+
+	// Assert
+	assert.False(t, ok)
+	assert.Equal(t, "", Subject(ctx))
+	assert.Equal(t, "", Tenant(ctx))
+	assert.Nil(t, Roles(ctx))
+	assert.Equal(t, "claims not found in context", DecodeClaims(ctx, &struct{}{}).Error())
+}
+
+// TestDecodeClaims_Ok checks that the claims are decoded into a custom struct
+func TestDecodeClaims_Ok(t *testing.T) {
+	// Arrange
+	type customClaims struct {
+		Subject string   `json:"sub"`
+		Roles   []string `json:"roles"`
+		Level   int      `json:"level"`
+	}
+	ctx := ContextWithClaims(context.Background(), jwt.MapClaims{"sub": "test-subject", "roles": []interface{}{"admin"}, "level": float64(3)})
+	var target customClaims
+
+	// Act
+	err := DecodeClaims(ctx, &target)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, customClaims{Subject: "test-subject", Roles: []string{"admin"}, Level: 3}, target)
+}
+
+// TestDecodeClaims_InvalidTarget checks that an error is returned when the claims do not fit the target struct
+func TestDecodeClaims_InvalidTarget(t *testing.T) {
+	// Arrange
+	ctx := ContextWithClaims(context.Background(), jwt.MapClaims{"level": "high"})
+	var target struct {
+		Level int `json:"level"`
+	}
+
+	// Act
+	err := DecodeClaims(ctx, &target)
+
+	// Assert
+	assert.Contains(t, err.Error(), "failed to decode claims: ")
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+const (
+	defaultIntrospectionMaxCacheTTL = 5 * time.Minute
+	defaultIntrospectionTimeout     = 10 * time.Second
+)
+
+// Introspector is a TokenValidator for opaque tokens that calls an OAuth2 token introspection endpoint (RFC 7662),
+// caching the active tokens until their expiration and at most for MaxCacheTTL
+type Introspector struct {
+	// ClientID and ClientSecret authenticate the calls to the introspection endpoint with basic authentication when not empty
+	ClientID     string
+	ClientSecret string
+	// MaxCacheTTL is the maximum time during which an active token is cached, the results are not cached when zero or negative
+	MaxCacheTTL time.Duration
+	// HTTPClient is the client used to call the introspection endpoint
+	HTTPClient *http.Client
+	// Rules are the checks applied to the claims of the active tokens
+	Rules []ClaimRule
+
+	url   string
+	mu    sync.Mutex
+	cache map[string]introspectionResult
+}
+
+type introspectionResult struct {
+	claims    jwt.MapClaims
+	expiresAt time.Time
+}
+
+// NewIntrospector creates a new Introspector for the given introspection endpoint and client credentials
+func NewIntrospector(url, clientID, clientSecret string, rules ...ClaimRule) *Introspector {
+	return &Introspector{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		MaxCacheTTL:  defaultIntrospectionMaxCacheTTL,
+		HTTPClient:   &http.Client{Timeout: defaultIntrospectionTimeout},
+		Rules:        rules,
+		url:          url,
+		cache:        make(map[string]introspectionResult),
+	}
+}
+
+// Validate introspects the token and returns its claims, returning an UnauthorizedErr when the token is not active,
+// an UnauthenticatedErr when its claims do not satisfy the rules and a ServiceUnavailableErr when the endpoint cannot be called
+func (i *Introspector) Validate(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	key := hashToken(tokenString)
+	claims, found := i.cached(key)
+	if !found {
+		var err error
+		claims, err = i.introspect(ctx, tokenString)
+		if err != nil {
+			return nil, err
+		}
+		i.store(key, claims)
+	}
+
+	if err := ApplyRules(claims, i.Rules); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (i *Introspector) introspect(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	form := url.Values{"token": {tokenString}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, wrappers.NewServiceUnavailableErr(fmt.Errorf("failed to introspect token: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.ClientID), url.QueryEscape(i.ClientSecret))
+	}
+
+	resp, err := i.HTTPClient.Do(req)
+	if err != nil {
+		return nil, wrappers.NewServiceUnavailableErr(fmt.Errorf("failed to introspect token: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, wrappers.NewServiceUnavailableErr(fmt.Errorf("failed to introspect token: unexpected status code %d", resp.StatusCode))
+	}
+
+	claims := jwt.MapClaims{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, wrappers.NewServiceUnavailableErr(fmt.Errorf("failed to decode introspection response: %w", err))
+	}
+
+	if active, _ := claims["active"].(bool); !active {
+		return nil, wrappers.NewUnauthorizedErr(errors.New("invalid token: Token is not active"))
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), false) {
+		return nil, wrappers.NewUnauthorizedErr(errors.New("invalid token: Token is expired"))
+	}
+	return claims, nil
+}
+
+func (i *Introspector) cached(key string) (jwt.MapClaims, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	result, ok := i.cache[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(result.expiresAt) {
+		delete(i.cache, key)
+		return nil, false
+	}
+	return result.claims, true
+}
+
+func (i *Introspector) store(key string, claims jwt.MapClaims) {
+	if i.MaxCacheTTL <= 0 {
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(i.MaxCacheTTL)
+	if exp, ok := claims["exp"].(float64); ok && time.Unix(int64(exp), 0).Before(expiresAt) {
+		expiresAt = time.Unix(int64(exp), 0)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.cache == nil {
+		i.cache = make(map[string]introspectionResult)
+	}
+	for k, result := range i.cache {
+		if now.After(result.expiresAt) {
+			delete(i.cache, k)
+		}
+	}
+	i.cache[key] = introspectionResult{claims: claims, expiresAt: expiresAt}
+}
+
+func hashToken(tokenString string) string {
+	hash := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+type introspectionServer struct {
+	*httptest.Server
+	requests atomic.Int32
+}
+
+// newIntrospectionServer starts an introspection endpoint stand-in returning the responses by token, and inactive for unknown tokens
+func newIntrospectionServer(t *testing.T, responses map[string]map[string]interface{}) *introspectionServer {
+	t.Helper()
+
+	s := &introspectionServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "test-client" || clientSecret != "test-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.PostFormValue("token_type_hint") != "access_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response, ok := responses[r.PostFormValue("token")]
+		if !ok {
+			response = map[string]interface{}{"active": false}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// TestIntrospector_Validate checks that the Introspector correctly handles all expected scenarios
+func TestIntrospector_Validate(t *testing.T) {
+	server := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"active-token":  {"active": true, "sub": "test-subject", "scope": "read write", "exp": time.Now().Add(time.Hour).Unix()},
+		"expired-token": {"active": true, "sub": "test-subject", "exp": time.Now().Add(-time.Minute).Unix()},
+		"read-token":    {"active": true, "sub": "test-subject", "scope": "read"},
+	})
+	introspector := NewIntrospector(server.URL, "test-client", "test-secret", ScopeIncludes("write"))
+
+	cases := []struct {
+		name        string
+		token       string
+		expectedErr error
+		expectedMsg string
+	}{
+		{"Active token", "active-token", nil, ""},
+		{"Inactive token", "unknown-token", wrappers.UnauthorizedErr, "invalid token: Token is not active"},
+		{"Expired token", "expired-token", wrappers.UnauthorizedErr, "invalid token: Token is expired"},
+		{"Missing scope", "read-token", wrappers.UnauthenticatedErr, "insufficient permissions: required scope 'write' not granted"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			claims, err := introspector.Validate(context.Background(), tt.token)
+
+			// Assert
+			if tt.expectedErr == nil {
+				assert.Nil(t, err)
+				assert.Equal(t, "test-subject", claims["sub"])
+			} else {
+				assert.True(t, errors.Is(err, tt.expectedErr))
+				assert.Equal(t, tt.expectedMsg, err.Error())
+			}
+		})
+	}
+}
+
+// TestIntrospector_Cache checks that the active tokens are cached until their expiration and the inactive ones are not cached
+func TestIntrospector_Cache(t *testing.T) {
+	// Arrange
+	server := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"active-token": {"active": true, "sub": "test-subject", "exp": time.Now().Add(time.Hour).Unix()},
+	})
+	introspector := NewIntrospector(server.URL, "test-client", "test-secret")
+
+	// Act
+	for i := 0; i < 3; i++ {
+		introspector.Validate(context.Background(), "active-token")
+		introspector.Validate(context.Background(), "unknown-token")
+	}
+
+	// Assert
+	assert.Equal(t, int32(4), server.requests.Load())
+}
+
+// TestIntrospector_StructLiteral checks that an Introspector not created with NewIntrospector caches the active tokens
+func TestIntrospector_StructLiteral(t *testing.T) {
+	// Arrange
+	server := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"active-token": {"active": true, "sub": "test-subject"},
+	})
+	introspector := &Introspector{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		MaxCacheTTL:  time.Minute,
+		HTTPClient:   http.DefaultClient,
+		url:          server.URL,
+	}
+
+	// Act
+	_, err1 := introspector.Validate(context.Background(), "active-token")
+	_, err2 := introspector.Validate(context.Background(), "active-token")
+
+	// Assert
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, int32(1), server.requests.Load())
+}
+
+// TestIntrospector_CacheExpiration checks that the cached results are introspected again once MaxCacheTTL has elapsed
+func TestIntrospector_CacheExpiration(t *testing.T) {
+	// Arrange
+	server := newIntrospectionServer(t, map[string]map[string]interface{}{
+		"active-token": {"active": true, "sub": "test-subject"},
+	})
+	introspector := NewIntrospector(server.URL, "test-client", "test-secret")
+	introspector.MaxCacheTTL = 10 * time.Millisecond
+	introspector.Validate(context.Background(), "active-token")
+
+	// Act
+	time.Sleep(20 * time.Millisecond)
+	_, err := introspector.Validate(context.Background(), "active-token")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+// TestIntrospector_EndpointError checks that a ServiceUnavailableErr is returned when the endpoint does not respond successfully
+func TestIntrospector_EndpointError(t *testing.T) {
+	// Arrange
+	server := newIntrospectionServer(t, nil)
+	introspector := NewIntrospector(server.URL, "test-client", "wrong-secret")
+
+	// Act
+	_, err := introspector.Validate(context.Background(), "active-token")
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.ServiceUnavailableErr))
+	assert.Equal(t, "failed to introspect token: unexpected status code 401", err.Error())
+}
@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/sergicanet9/scv-go-tools/v4/repository"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenPair is the pair of access and refresh tokens returned on login and refresh
+type TokenPair struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// RefreshToken is the entity stored for each refresh token, identified by the hash of the token
+// All the refresh tokens rotated from the same login share the FamilyID
+type RefreshToken struct {
+	ID        string                 `json:"id,omitempty" bson:"_id,omitempty"`
+	TokenHash string                 `json:"token_hash" bson:"token_hash"`
+	FamilyID  string                 `json:"family_id" bson:"family_id"`
+	Subject   string                 `json:"subject" bson:"subject"`
+	Claims    map[string]interface{} `json:"claims,omitempty" bson:"claims,omitempty"`
+	Used      bool                   `json:"used" bson:"used"`
+	Revoked   bool                   `json:"revoked" bson:"revoked"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time              `json:"expires_at" bson:"expires_at"`
+}
+
+// TokenService issues access tokens signed with the SigningKey and rotating refresh tokens stored through a repository.Repository,
+// which must be configured with RefreshToken as target entity
+type TokenService struct {
+	// Key signs the access tokens, and verifies them in the Validator
+	Key SigningKey
+	// Issuer is set as iss claim of the access tokens when not empty
+	Issuer string
+	// Audience is set as aud claim of the access tokens when not empty
+	Audience string
+	// AccessTokenTTL is the lifetime of the access tokens
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is the lifetime of the refresh tokens
+	RefreshTokenTTL time.Duration
+	// RefreshTokens stores the refresh tokens, refresh tokens are not issued when nil
+	RefreshTokens repository.Repository
+	// Revocations stores the revoked access tokens and subjects, revocation is not supported when nil
+	Revocations RevocationList
+}
+
+// NewTokenService creates a new TokenService with the given signing key, refresh token repository and revocation list
+func NewTokenService(key SigningKey, refreshTokens repository.Repository, revocations RevocationList) *TokenService {
+	return &TokenService{
+		Key:             key,
+		AccessTokenTTL:  defaultAccessTokenTTL,
+		RefreshTokenTTL: defaultRefreshTokenTTL,
+		RefreshTokens:   refreshTokens,
+		Revocations:     revocations,
+	}
+}
+
+// Validator returns a Validator for the access tokens issued by the service, checking its issuer, audience and revocation list
+func (s *TokenService) Validator(rules ...ClaimRule) *Validator {
+	validator := NewValidator(s.Key, rules...)
+	validator.Issuer = s.Issuer
+	validator.Audience = s.Audience
+	validator.Revocations = s.Revocations
+	return validator
+}
+
+// IssueAccessToken issues an access token for the subject with the given additional claims, returning it with its expiration
+func (s *TokenService) IssueAccessToken(subject string, claims map[string]interface{}) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.AccessTokenTTL)
+
+	tokenClaims := jwt.MapClaims{}
+	for name, value := range claims {
+		tokenClaims[name] = value
+	}
+	tokenClaims["sub"] = subject
+	tokenClaims["jti"] = uuid.NewString()
+	// iat has microsecond precision so that the tokens issued right after a LogoutAll are not revoked by it
+	tokenClaims["iat"] = float64(now.UnixMicro()) / 1e6
+	tokenClaims["exp"] = expiresAt.Unix()
+	if s.Issuer != "" {
+		tokenClaims["iss"] = s.Issuer
+	}
+	if s.Audience != "" {
+		tokenClaims["aud"] = s.Audience
+	}
+
+	accessToken, err := s.Key.SignToken(tokenClaims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return accessToken, expiresAt, nil
+}
+
+// Issue issues an access token and a refresh token starting a new refresh token family for the subject with the given additional claims
+func (s *TokenService) Issue(ctx context.Context, subject string, claims map[string]interface{}) (TokenPair, error) {
+	return s.issuePair(ctx, subject, claims, uuid.NewString())
+}
+
+// Refresh exchanges a refresh token for a new token pair, rotating the refresh token.
+// Presenting an already used refresh token revokes its whole family, as it means that the token has been leaked.
+// As the Repository has no compare-and-swap, concurrent refreshes of the same token are not detected as reuse
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	stored, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if stored.Used {
+		if err := s.revokeFamily(ctx, stored.FamilyID); err != nil {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, wrappers.NewUnauthorizedErr(errors.New("invalid refresh token: reuse detected"))
+	}
+	if stored.Revoked || time.Now().After(stored.ExpiresAt) {
+		return TokenPair{}, wrappers.NewUnauthorizedErr(errors.New("invalid refresh token: revoked or expired"))
+	}
+
+	id := stored.ID
+	stored.ID = ""
+	stored.Used = true
+	if err := s.RefreshTokens.Update(ctx, id, stored); err != nil {
+		return TokenPair{}, err
+	}
+
+	return s.issuePair(ctx, stored.Subject, stored.Claims, stored.FamilyID)
+}
+
+// RevokeRefreshToken revokes the family of the refresh token, ending the session it belongs to
+func (s *TokenService) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	stored, err := s.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.revokeFamily(ctx, stored.FamilyID)
+}
+
+// RevokeAccessToken adds the jti of the access token claims to the revocation list until the token expires
+func (s *TokenService) RevokeAccessToken(ctx context.Context, claims jwt.MapClaims) error {
+	if s.Revocations == nil {
+		return errors.New("revocation list not configured")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return wrappers.NewValidationErr(errors.New("jti claim not found"))
+	}
+
+	expiresAt := time.Now().Add(s.AccessTokenTTL)
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0)
+	}
+	return s.Revocations.RevokeToken(ctx, jti, expiresAt)
+}
+
+// LogoutAll revokes all the access tokens issued to the subject before now and all its refresh tokens
+func (s *TokenService) LogoutAll(ctx context.Context, subject string) error {
+	if s.Revocations != nil {
+		if err := s.Revocations.RevokeSubject(ctx, subject, time.Now()); err != nil {
+			return err
+		}
+	}
+	if s.RefreshTokens == nil {
+		return nil
+	}
+	return s.revokeRefreshTokens(ctx, map[string]interface{}{"subject": subject, "revoked": false})
+}
+
+func (s *TokenService) issuePair(ctx context.Context, subject string, claims map[string]interface{}, familyID string) (TokenPair, error) {
+	accessToken, expiresAt, err := s.IssueAccessToken(subject, claims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	pair := TokenPair{AccessToken: accessToken, ExpiresAt: expiresAt}
+	if s.RefreshTokens == nil {
+		return pair, nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return TokenPair{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	pair.RefreshToken = base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now().UTC()
+	_, err = s.RefreshTokens.Create(ctx, RefreshToken{
+		TokenHash: hashToken(pair.RefreshToken),
+		FamilyID:  familyID,
+		Subject:   subject,
+		Claims:    claims,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.RefreshTokenTTL),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return pair, nil
+}
+
+func (s *TokenService) findRefreshToken(ctx context.Context, refreshToken string) (*RefreshToken, error) {
+	if s.RefreshTokens == nil {
+		return nil, errors.New("refresh token repository not configured")
+	}
+
+	take := 1
+	entities, err := s.RefreshTokens.Get(ctx, map[string]interface{}{"token_hash": hashToken(refreshToken)}, nil, &take)
+	if err != nil {
+		if errors.Is(err, wrappers.NonExistentErr) {
+			return nil, wrappers.NewUnauthorizedErr(errors.New("invalid refresh token: not found"))
+		}
+		return nil, err
+	}
+
+	stored, ok := entities[0].(*RefreshToken)
+	if !ok {
+		return nil, fmt.Errorf("unexpected refresh token entity %T", entities[0])
+	}
+	return stored, nil
+}
+
+func (s *TokenService) revokeFamily(ctx context.Context, familyID string) error {
+	return s.revokeRefreshTokens(ctx, map[string]interface{}{"family_id": familyID, "revoked": false})
+}
+
+func (s *TokenService) revokeRefreshTokens(ctx context.Context, filter map[string]interface{}) error {
+	entities, err := s.RefreshTokens.Get(ctx, filter, nil, nil)
+	if err != nil {
+		if errors.Is(err, wrappers.NonExistentErr) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entity := range entities {
+		stored, ok := entity.(*RefreshToken)
+		if !ok {
+			return fmt.Errorf("unexpected refresh token entity %T", entity)
+		}
+		id := stored.ID
+		stored.ID = ""
+		stored.Revoked = true
+		if err := s.RefreshTokens.Update(ctx, id, stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTokenService() (*TokenService, *memoryRepository) {
+	repo := newMemoryRepository(RefreshToken{})
+	service := NewTokenService(HMACSigningKey([]byte("test-secret")), repo, NewMemoryRevocationList())
+	service.Issuer = "test-issuer"
+	service.Audience = "test-audience"
+	return service, repo
+}
+
+// TestTokenService_Issue checks that the issued access token contains the registered and additional claims and is accepted by the validator
+func TestTokenService_Issue(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, repo := newTestTokenService()
+
+	// Act
+	pair, err := service.Issue(ctx, "test-subject", map[string]interface{}{"roles": []string{"admin"}})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotEmpty(t, pair.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(defaultAccessTokenTTL), pair.ExpiresAt, time.Second)
+	assert.Len(t, repo.documents, 1)
+
+	claims, err := service.Validator().Validate(ctx, pair.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-subject", claims["sub"])
+	assert.Equal(t, "test-issuer", claims["iss"])
+	assert.Equal(t, "test-audience", claims["aud"])
+	assert.Equal(t, []interface{}{"admin"}, claims["roles"])
+	assert.NotEmpty(t, claims["jti"])
+}
+
+// TestTokenService_WithoutRefreshTokens checks that no refresh token is issued when the repository is not configured
+func TestTokenService_WithoutRefreshTokens(t *testing.T) {
+	// Arrange
+	service := NewTokenService(HMACSigningKey([]byte("test-secret")), nil, nil)
+
+	// Act
+	pair, err := service.Issue(context.Background(), "test-subject", nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.Empty(t, pair.RefreshToken)
+}
+
+// TestTokenService_Refresh checks that a refresh token is exchanged for a new pair with the original claims and cannot be used twice
+func TestTokenService_Refresh(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newTestTokenService()
+	pair, _ := service.Issue(ctx, "test-subject", map[string]interface{}{"tenant": "test-tenant"})
+
+	// Act
+	refreshed, err := service.Refresh(ctx, pair.RefreshToken)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotEqual(t, pair.RefreshToken, refreshed.RefreshToken)
+	claims, err := service.Validator().Validate(ctx, refreshed.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-tenant", claims["tenant"])
+}
+
+// TestTokenService_RefreshReuse checks that reusing a rotated refresh token revokes the whole family
+func TestTokenService_RefreshReuse(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newTestTokenService()
+	pair, _ := service.Issue(ctx, "test-subject", nil)
+	refreshed, _ := service.Refresh(ctx, pair.RefreshToken)
+
+	// Act
+	_, reuseErr := service.Refresh(ctx, pair.RefreshToken)
+	_, familyErr := service.Refresh(ctx, refreshed.RefreshToken)
+
+	// Assert
+	assert.True(t, errors.Is(reuseErr, wrappers.UnauthorizedErr))
+	assert.Equal(t, "invalid refresh token: reuse detected", reuseErr.Error())
+	assert.Equal(t, "invalid refresh token: revoked or expired", familyErr.Error())
+}
+
+// TestTokenService_RefreshInvalid checks that unknown and expired refresh tokens are rejected
+func TestTokenService_RefreshInvalid(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newTestTokenService()
+	service.RefreshTokenTTL = -time.Minute
+	pair, _ := service.Issue(ctx, "test-subject", nil)
+
+	// Act
+	_, unknownErr := service.Refresh(ctx, "unknown")
+	_, expiredErr := service.Refresh(ctx, pair.RefreshToken)
+
+	// Assert
+	assert.Equal(t, "invalid refresh token: not found", unknownErr.Error())
+	assert.Equal(t, "invalid refresh token: revoked or expired", expiredErr.Error())
+}
+
+// TestTokenService_RevokeRefreshToken checks that a revoked refresh token cannot be exchanged
+func TestTokenService_RevokeRefreshToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newTestTokenService()
+	pair, _ := service.Issue(ctx, "test-subject", nil)
+
+	// Act
+	err := service.RevokeRefreshToken(ctx, pair.RefreshToken)
+
+	// Assert
+	assert.Nil(t, err)
+	_, err = service.Refresh(ctx, pair.RefreshToken)
+	assert.Equal(t, "invalid refresh token: revoked or expired", err.Error())
+}
+
+// TestTokenService_RevokeAccessToken checks that a revoked access token is rejected by the validator while the others are still accepted
+func TestTokenService_RevokeAccessToken(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newTestTokenService()
+	revoked, _ := service.Issue(ctx, "test-subject", nil)
+	valid, _ := service.Issue(ctx, "test-subject", nil)
+	claims, _ := service.Validator().Validate(ctx, revoked.AccessToken)
+
+	// Act
+	err := service.RevokeAccessToken(ctx, claims)
+
+	// Assert
+	assert.Nil(t, err)
+	_, revokedErr := service.Validator().Validate(ctx, revoked.AccessToken)
+	assert.True(t, errors.Is(revokedErr, wrappers.UnauthorizedErr))
+	assert.Equal(t, "invalid token: Token has been revoked", revokedErr.Error())
+	_, validErr := service.Validator().Validate(ctx, valid.AccessToken)
+	assert.Nil(t, validErr)
+}
+
+// TestTokenService_RevokeAccessTokenWithoutJTI checks that a ValidationErr is returned when the claims do not contain a jti
+func TestTokenService_RevokeAccessTokenWithoutJTI(t *testing.T) {
+	// Arrange
+	service, _ := newTestTokenService()
+
+	// Act
+	err := service.RevokeAccessToken(context.Background(), jwt.MapClaims{"sub": "test-subject"})
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.ValidationErr))
+}
+
+// TestTokenService_LogoutAll checks that all the access and refresh tokens of the subject are revoked, and only those
+func TestTokenService_LogoutAll(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newTestTokenService()
+	first, _ := service.Issue(ctx, "test-subject", nil)
+	second, _ := service.Issue(ctx, "test-subject", nil)
+	other, _ := service.Issue(ctx, "other-subject", nil)
+
+	// Act
+	err := service.LogoutAll(ctx, "test-subject")
+
+	// Assert
+	assert.Nil(t, err)
+	_, accessErr := service.Validator().Validate(ctx, first.AccessToken)
+	assert.Equal(t, "invalid token: Token has been revoked", accessErr.Error())
+	_, refreshErr := service.Refresh(ctx, second.RefreshToken)
+	assert.Equal(t, "invalid refresh token: revoked or expired", refreshErr.Error())
+	_, otherErr := service.Validator().Validate(ctx, other.AccessToken)
+	assert.Nil(t, otherErr)
+	_, otherErr = service.Refresh(ctx, other.RefreshToken)
+	assert.Nil(t, otherErr)
+}
+
+// TestTokenService_LoginAfterLogoutAll checks that the tokens issued right after logging out everywhere are not revoked
+func TestTokenService_LoginAfterLogoutAll(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, _ := newTestTokenService()
+	assert.Nil(t, service.LogoutAll(ctx, "test-subject"))
+
+	// Act
+	pair, err := service.Issue(ctx, "test-subject", nil)
+
+	// Assert
+	assert.Nil(t, err)
+	_, accessErr := service.Validator().Validate(ctx, pair.AccessToken)
+	assert.Nil(t, accessErr)
+	_, refreshErr := service.Refresh(ctx, pair.RefreshToken)
+	assert.Nil(t, refreshErr)
+}
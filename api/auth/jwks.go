@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSTTL                = time.Hour
+	defaultJWKSMinRefreshInterval = 30 * time.Second
+	defaultJWKSTimeout            = 10 * time.Second
+)
+
+// JWKS is a KeyProvider that fetches the keys from a JWKS document published by an identity provider
+// The keys are cached for the TTL and refreshed on demand when a token has an unknown kid, at most once per MinRefreshInterval
+// The concurrent refreshes are collapsed into a single fetch, while the cached keys keep being served without waiting for it
+type JWKS struct {
+	// TTL is the time during which the fetched keys are used without refreshing them
+	TTL time.Duration
+	// MinRefreshInterval is the minimum time between two fetches, limiting the refreshes triggered by unknown kids
+	MinRefreshInterval time.Duration
+	// HTTPClient is the client used to fetch the JWKS document
+	HTTPClient *http.Client
+
+	url         string
+	group       singleflight.Group
+	mu          sync.Mutex
+	keys        map[string]jwksKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+type jwksKey struct {
+	provider KeyProvider
+	alg      string
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewJWKS creates a new JWKS key provider for the document published at the given URL
+func NewJWKS(url string) *JWKS {
+	return &JWKS{
+		TTL:                defaultJWKSTTL,
+		MinRefreshInterval: defaultJWKSMinRefreshInterval,
+		HTTPClient:         &http.Client{Timeout: defaultJWKSTimeout},
+		url:                url,
+	}
+}
+
+// VerificationKey returns the key identified by the kid header of the token, fetching the JWKS document when needed
+func (j *JWKS) VerificationKey(token *jwt.Token) (interface{}, error) {
+	return j.VerificationKeyContext(context.Background(), token)
+}
+
+// VerificationKeyContext returns the key identified by the kid header of the token,
+// fetching the JWKS document when needed for no longer than the context allows
+func (j *JWKS) VerificationKeyContext(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("kid header not found")
+	}
+
+	key, err := j.key(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != "" && key.alg != token.Method.Alg() {
+		return nil, errInvalidSigningMethod
+	}
+	return key.provider.VerificationKey(token)
+}
+
+// Refresh fetches the JWKS document and replaces the cached keys, which can be used to warm up the cache at startup
+func (j *JWKS) Refresh(ctx context.Context) error {
+	return j.refresh(ctx, true)
+}
+
+func (j *JWKS) key(ctx context.Context, kid string) (jwksKey, error) {
+	keys, fresh := j.cached()
+	key, found := keys[kid]
+	if found && fresh {
+		return key, nil
+	}
+
+	if err := j.refresh(ctx, false); err != nil {
+		if !found {
+			return jwksKey{}, err
+		}
+		return key, nil
+	}
+
+	// the expired keys are only kept when the refresh was skipped by the MinRefreshInterval
+	if keys, fresh = j.cached(); fresh {
+		key, found = keys[kid]
+	}
+	if !found {
+		return jwksKey{}, fmt.Errorf("unknown kid %s", kid)
+	}
+	return key, nil
+}
+
+// cached returns the cached keys, which are never modified but replaced on every refresh, and whether they are within their TTL
+func (j *JWKS) cached() (map[string]jwksKey, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.keys, time.Since(j.fetchedAt) < j.TTL
+}
+
+// refresh fetches the JWKS document once for all the concurrent callers, each of them waiting for it until its context is done
+// The fetch is detached from the cancellation of the caller that started it, so that it still serves the others when that caller goes away
+// Unless forced, the document is not fetched again within the MinRefreshInterval of the last fetch that was not cancelled
+func (j *JWKS) refresh(ctx context.Context, force bool) error {
+	flight := "on-demand"
+	if force {
+		flight = "forced"
+	}
+
+	result := j.group.DoChan(flight, func() (interface{}, error) {
+		j.mu.Lock()
+		if !force && time.Since(j.lastAttempt) < j.MinRefreshInterval {
+			j.mu.Unlock()
+			return nil, nil
+		}
+		j.mu.Unlock()
+
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultJWKSTimeout)
+		defer cancel()
+		keys, err := j.fetch(fetchCtx)
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if !errors.Is(err, context.Canceled) {
+			j.lastAttempt = time.Now()
+		}
+		if err != nil {
+			return nil, err
+		}
+		j.keys = keys
+		j.fetchedAt = j.lastAttempt
+		return nil, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r := <-result:
+		return r.Err
+	}
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]jwksKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	resp, err := j.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status code %d", resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]jwksKey, len(document.Keys))
+	for _, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		publicKey, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		provider, err := PublicKey(publicKey)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = jwksKey{provider: provider, alg: k.Alg}
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,347 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type jwksServer struct {
+	*httptest.Server
+	keys     atomic.Value
+	requests atomic.Int32
+	status   atomic.Int32
+	gate     atomic.Value
+}
+
+func newJWKSServer(t *testing.T, keys ...map[string]string) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{}
+	s.keys.Store(keys)
+	s.status.Store(http.StatusOK)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if gate, ok := s.gate.Load().(chan struct{}); ok {
+			<-gate
+		}
+		if status := int(s.status.Load()); status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": s.keys.Load()})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256", "n": encodeBigInt(key.N), "e": encodeBigInt(big.NewInt(int64(key.E)))}
+}
+
+// TestJWKS_Ok checks that tokens signed with RSA, ECDSA and Ed25519 keys are verified with the keys of the JWKS document
+func TestJWKS_Ok(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	server := newJWKSServer(t,
+		rsaJWK("rsa", &rsaKey.PublicKey),
+		map[string]string{"kty": "EC", "kid": "ec", "crv": "P-384", "x": encodeBigInt(ecKey.X), "y": encodeBigInt(ecKey.Y)},
+		map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(edPublic)},
+	)
+	jwks := NewJWKS(server.URL)
+
+	cases := []struct {
+		kid    string
+		method jwt.SigningMethod
+		key    interface{}
+	}{
+		{"rsa", jwt.SigningMethodRS256, rsaKey},
+		{"ec", jwt.SigningMethodES384, ecKey},
+		{"ed", jwt.SigningMethodEdDSA, edPrivate},
+	}
+
+	for _, c := range cases {
+		t.Run(c.kid, func(t *testing.T) {
+			// Act
+			claims, err := ParseToken(signToken(t, c.method, c.key, c.kid), jwks)
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, "test-subject", claims["sub"])
+		})
+	}
+	assert.Equal(t, int32(1), server.requests.Load())
+}
+
+// TestJWKS_AlgorithmMismatch checks that a token signed with a method different from the alg of the JWK is rejected
+func TestJWKS_AlgorithmMismatch(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	jwks := NewJWKS(server.URL)
+	token := jwt.New(jwt.SigningMethodPS256)
+	token.Header["kid"] = "rsa"
+
+	// Act
+	_, err := jwks.VerificationKey(token)
+
+	// Assert
+	assert.Equal(t, errInvalidSigningMethod, err)
+}
+
+// TestJWKS_MissingKid checks that a token without kid header is rejected without fetching the JWKS document
+func TestJWKS_MissingKid(t *testing.T) {
+	// Arrange
+	server := newJWKSServer(t)
+	jwks := NewJWKS(server.URL)
+
+	// Act
+	_, err := jwks.VerificationKey(jwt.New(jwt.SigningMethodRS256))
+
+	// Assert
+	assert.Equal(t, "kid header not found", err.Error())
+	assert.Equal(t, int32(0), server.requests.Load())
+}
+
+// TestJWKS_SkipsEncryptionKeys checks that the keys not intended for signatures are ignored
+func TestJWKS_SkipsEncryptionKeys(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key := rsaJWK("enc", &rsaKey.PublicKey)
+	key["use"] = "enc"
+	server := newJWKSServer(t, key)
+	jwks := NewJWKS(server.URL)
+
+	// Act
+	_, err := ParseToken(signToken(t, jwt.SigningMethodRS256, rsaKey, "enc"), jwks)
+
+	// Assert
+	assert.Contains(t, err.Error(), "unknown kid enc")
+}
+
+// TestJWKS_RefreshOnUnknownKid checks that an unknown kid triggers a refresh that picks up rotated keys
+func TestJWKS_RefreshOnUnknownKid(t *testing.T) {
+	// Arrange
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("old", &oldKey.PublicKey))
+	jwks := NewJWKS(server.URL)
+	jwks.MinRefreshInterval = 0
+	assert.Nil(t, jwks.Refresh(context.Background()))
+	server.keys.Store([]map[string]string{rsaJWK("new", &newKey.PublicKey)})
+
+	// Act
+	claims, err := ParseToken(signToken(t, jwt.SigningMethodRS256, newKey, "new"), jwks)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test-subject", claims["sub"])
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+// TestJWKS_RefreshRateLimited checks that unknown kids do not trigger more than one fetch per MinRefreshInterval
+func TestJWKS_RefreshRateLimited(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	jwks := NewJWKS(server.URL)
+	tokenString := signToken(t, jwt.SigningMethodRS256, rsaKey, "unknown")
+
+	// Act
+	for i := 0; i < 5; i++ {
+		_, err := ParseToken(tokenString, jwks)
+		assert.Contains(t, err.Error(), "unknown kid unknown")
+	}
+
+	// Assert
+	assert.Equal(t, int32(1), server.requests.Load())
+}
+
+// TestJWKS_ExpiredCache checks that the keys are fetched again once the TTL has elapsed
+func TestJWKS_ExpiredCache(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	jwks := NewJWKS(server.URL)
+	jwks.TTL = 10 * time.Millisecond
+	jwks.MinRefreshInterval = 0
+	tokenString := signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa")
+	_, err := ParseToken(tokenString, jwks)
+	assert.Nil(t, err)
+
+	// Act
+	time.Sleep(20 * time.Millisecond)
+	_, err = ParseToken(tokenString, jwks)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+// TestJWKS_StaleKeysOnFetchError checks that the cached keys are still used when the refresh of an expired cache fails
+func TestJWKS_StaleKeysOnFetchError(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	jwks := NewJWKS(server.URL)
+	jwks.TTL = 0
+	jwks.MinRefreshInterval = 0
+	assert.Nil(t, jwks.Refresh(context.Background()))
+	server.status.Store(http.StatusInternalServerError)
+
+	// Act
+	_, err := ParseToken(signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa"), jwks)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), server.requests.Load())
+}
+
+// TestJWKS_FetchError checks that an error is returned when the JWKS document cannot be fetched and no keys are cached
+func TestJWKS_FetchError(t *testing.T) {
+	// Arrange
+	server := newJWKSServer(t)
+	server.status.Store(http.StatusNotFound)
+	jwks := NewJWKS(server.URL)
+
+	// Act
+	err := jwks.Refresh(context.Background())
+
+	// Assert
+	assert.Equal(t, "failed to fetch JWKS: unexpected status code 404", err.Error())
+}
+
+// TestJWKS_CachedKeysDuringRefresh checks that the cached keys are served while a refresh is in progress
+func TestJWKS_CachedKeysDuringRefresh(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	jwks := NewJWKS(server.URL)
+	jwks.MinRefreshInterval = 0
+	assert.Nil(t, jwks.Refresh(context.Background()))
+
+	gate := make(chan struct{})
+	defer close(gate)
+	server.gate.Store(gate)
+	go ParseToken(signToken(t, jwt.SigningMethodRS256, rsaKey, "unknown"), jwks)
+	assert.Eventually(t, func() bool { return server.requests.Load() == 2 }, time.Second, time.Millisecond)
+
+	// Act
+	claims, err := ParseToken(signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa"), jwks)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test-subject", claims["sub"])
+}
+
+// TestJWKS_ConcurrentRefresh checks that the refreshes triggered at the same time are collapsed into a single fetch
+func TestJWKS_ConcurrentRefresh(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	gate := make(chan struct{})
+	server.gate.Store(gate)
+	jwks := NewJWKS(server.URL)
+	jwks.MinRefreshInterval = 0
+	tokenString := signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa")
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ParseToken(tokenString, jwks)
+			errs <- err
+		}()
+	}
+	assert.Eventually(t, func() bool { return server.requests.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int32(1), server.requests.Load())
+}
+
+// TestJWKS_ContextDone checks that the validation stops waiting for the JWKS document when the context of the call is done
+func TestJWKS_ContextDone(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	gate := make(chan struct{})
+	defer close(gate)
+	server.gate.Store(gate)
+	validator := NewValidator(NewJWKS(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := validator.Validate(ctx, signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa"))
+
+	// Assert
+	assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+}
+
+// TestJWKS_FirstCallerCancelled checks that the refresh started by a caller whose context is cancelled still serves the other callers
+func TestJWKS_FirstCallerCancelled(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newJWKSServer(t, rsaJWK("rsa", &rsaKey.PublicKey))
+	gate := make(chan struct{})
+	server.gate.Store(gate)
+	validator := NewValidator(NewJWKS(server.URL))
+	tokenString := signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := validator.Validate(ctx, tokenString)
+		firstErr <- err
+	}()
+	assert.Eventually(t, func() bool { return server.requests.Load() == 1 }, time.Second, time.Millisecond)
+
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := validator.Validate(context.Background(), tokenString)
+		secondErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// Act
+	cancel()
+	assert.Contains(t, (<-firstErr).Error(), context.Canceled.Error())
+	close(gate)
+
+	// Assert
+	assert.Nil(t, <-secondErr)
+	_, err := validator.Validate(context.Background(), tokenString)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), server.requests.Load())
+}
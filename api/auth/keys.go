@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var errInvalidSigningMethod = errors.New("signin method not valid")
+
+// KeyProvider resolves the key used to verify the signature of a JWT token
+type KeyProvider interface {
+	VerificationKey(token *jwt.Token) (interface{}, error)
+}
+
+// ContextKeyProvider is a KeyProvider that can resolve the keys within the context of the call,
+// so that the remote fetches it may need are cancelled along with the call
+type ContextKeyProvider interface {
+	KeyProvider
+	VerificationKeyContext(ctx context.Context, token *jwt.Token) (interface{}, error)
+}
+
+// KeySet is a KeyProvider that selects the key by the kid header of the token
+type KeySet map[string]KeyProvider
+
+type hmacKey []byte
+
+type publicKey struct {
+	key crypto.PublicKey
+}
+
+// HMACKey returns a KeyProvider verifying HS256, HS384 and HS512 tokens with the given shared secret
+func HMACKey(secret []byte) KeyProvider {
+	return hmacKey(secret)
+}
+
+// PublicKey returns a KeyProvider verifying tokens with the given RSA, ECDSA or Ed25519 public key,
+// only accepting the signing methods of its algorithm family (RS*/PS*, ES* or EdDSA respectively)
+func PublicKey(key crypto.PublicKey) (KeyProvider, error) {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return publicKey{key: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// PublicKeyFromPEM returns a KeyProvider verifying tokens with the PEM-encoded RSA, ECDSA or Ed25519 public key,
+// which can be provided as a PKIX public key, a PKCS1 RSA public key or a certificate
+func PublicKeyFromPEM(data []byte) (KeyProvider, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid public key: not PEM-encoded")
+	}
+
+	var key crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("invalid public key: unsupported PEM block type %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return PublicKey(key)
+}
+
+// VerificationKey returns the secret when the token is signed with an HMAC method
+func (k hmacKey) VerificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, errInvalidSigningMethod
+	}
+	return []byte(k), nil
+}
+
+// VerificationKey returns the public key when the token is signed with a method of its algorithm family
+func (k publicKey) VerificationKey(token *jwt.Token) (interface{}, error) {
+	var valid bool
+	switch k.key.(type) {
+	case *rsa.PublicKey:
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			valid = true
+		}
+	case *ecdsa.PublicKey:
+		_, valid = token.Method.(*jwt.SigningMethodECDSA)
+	case ed25519.PublicKey:
+		_, valid = token.Method.(*jwt.SigningMethodEd25519)
+	}
+
+	if !valid {
+		return nil, errInvalidSigningMethod
+	}
+	return k.key, nil
+}
+
+// VerificationKey returns the key of the set identified by the kid header of the token
+func (s KeySet) VerificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("kid header not found")
+	}
+
+	provider, ok := s[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %s", kid)
+	}
+	return provider.VerificationKey(token)
+}
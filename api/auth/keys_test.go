@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func publicKeyPEM(t *testing.T, key interface{}) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "test-subject"})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenString
+}
+
+// TestPublicKeyFromPEM_Ok checks that tokens signed with RSA, ECDSA and Ed25519 keys are verified with their PEM-encoded public keys
+func TestPublicKeyFromPEM_Ok(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+
+	cases := []struct {
+		name       string
+		method     jwt.SigningMethod
+		signingKey interface{}
+		publicKey  interface{}
+	}{
+		{"RS256", jwt.SigningMethodRS256, rsaKey, &rsaKey.PublicKey},
+		{"PS256", jwt.SigningMethodPS256, rsaKey, &rsaKey.PublicKey},
+		{"ES256", jwt.SigningMethodES256, ecKey, &ecKey.PublicKey},
+		{"EdDSA", jwt.SigningMethodEdDSA, edPrivate, edPublic},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := PublicKeyFromPEM(publicKeyPEM(t, tc.publicKey))
+			assert.Nil(t, err)
+
+			claims, err := ParseToken(signToken(t, tc.method, tc.signingKey, ""), keys)
+
+			assert.Nil(t, err)
+			assert.Equal(t, "test-subject", claims["sub"])
+		})
+	}
+}
+
+// TestPublicKeyFromPEM_PKCS1 checks that PublicKeyFromPEM accepts PKCS1 RSA public keys
+func TestPublicKeyFromPEM_PKCS1(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})
+
+	// Act
+	keys, err := PublicKeyFromPEM(data)
+
+	// Assert
+	assert.Nil(t, err)
+	_, err = ParseToken(signToken(t, jwt.SigningMethodRS256, rsaKey, ""), keys)
+	assert.Nil(t, err)
+}
+
+// TestPublicKeyFromPEM_Certificate checks that PublicKeyFromPEM accepts certificates
+func TestPublicKeyFromPEM_Certificate(t *testing.T) {
+	// Arrange
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &ecKey.PublicKey, ecKey)
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	// Act
+	keys, err := PublicKeyFromPEM(data)
+
+	// Assert
+	assert.Nil(t, err)
+	_, err = ParseToken(signToken(t, jwt.SigningMethodES256, ecKey, ""), keys)
+	assert.Nil(t, err)
+}
+
+// TestPublicKeyFromPEM_Invalid checks that PublicKeyFromPEM returns an error when the data is not a valid public key
+func TestPublicKeyFromPEM_Invalid(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []byte
+		expectedErr string
+	}{
+		{"Not PEM", []byte("test"), "invalid public key: not PEM-encoded"},
+		{"Unsupported block", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("test")}), "invalid public key: unsupported PEM block type PRIVATE KEY"},
+		{"Malformed key", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("test")}), "invalid public key: "},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := PublicKeyFromPEM(tc.data)
+
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+// TestPublicKey_UnsupportedType checks that PublicKey returns an error when the key type is not supported
+func TestPublicKey_UnsupportedType(t *testing.T) {
+	// Act
+	_, err := PublicKey("test")
+
+	// Assert
+	assert.Equal(t, "unsupported public key type string", err.Error())
+}
+
+// TestPublicKey_AlgorithmMismatch checks that a token signed with a method of another algorithm family is rejected
+func TestPublicKey_AlgorithmMismatch(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys, _ := PublicKey(&rsaKey.PublicKey)
+
+	// Act
+	_, err := ParseToken(signToken(t, jwt.SigningMethodES256, ecKey, ""), keys)
+
+	// Assert
+	assert.Equal(t, "invalid token: signin method not valid", err.Error())
+}
+
+// TestHMACKey_AlgorithmMismatch checks that HMACKey rejects tokens signed with asymmetric methods
+func TestHMACKey_AlgorithmMismatch(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	// Act
+	_, err := ParseToken(signToken(t, jwt.SigningMethodRS256, rsaKey, ""), HMACKey([]byte("test-secret")))
+
+	// Assert
+	assert.Equal(t, "invalid token: signin method not valid", err.Error())
+}
+
+// TestKeySet_Ok checks that KeySet verifies the token with the key identified by its kid header
+func TestKeySet_Ok(t *testing.T) {
+	// Arrange
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rsaProvider, _ := PublicKey(&rsaKey.PublicKey)
+	keys := KeySet{
+		"hmac": HMACKey([]byte("test-secret")),
+		"rsa":  rsaProvider,
+	}
+
+	// Act
+	hmacClaims, hmacErr := ParseToken(signToken(t, jwt.SigningMethodHS256, []byte("test-secret"), "hmac"), keys)
+	rsaClaims, rsaErr := ParseToken(signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa"), keys)
+
+	// Assert
+	assert.Nil(t, hmacErr)
+	assert.Nil(t, rsaErr)
+	assert.Equal(t, "test-subject", hmacClaims["sub"])
+	assert.Equal(t, "test-subject", rsaClaims["sub"])
+}
+
+// TestKeySet_MissingKid checks that KeySet rejects tokens without kid header
+func TestKeySet_MissingKid(t *testing.T) {
+	// Arrange
+	keys := KeySet{"hmac": HMACKey([]byte("test-secret"))}
+
+	// Act
+	_, err := ParseToken(signToken(t, jwt.SigningMethodHS256, []byte("test-secret"), ""), keys)
+
+	// Assert
+	assert.Equal(t, "invalid token: kid header not found", err.Error())
+}
+
+// TestKeySet_UnknownKid checks that KeySet rejects tokens with a kid not present in the set
+func TestKeySet_UnknownKid(t *testing.T) {
+	// Arrange
+	keys := KeySet{"hmac": HMACKey([]byte("test-secret"))}
+
+	// Act
+	_, err := ParseToken(signToken(t, jwt.SigningMethodHS256, []byte("test-secret"), "other"), keys)
+
+	// Assert
+	assert.Equal(t, "invalid token: unknown kid other", err.Error())
+}
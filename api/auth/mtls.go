@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// CertificateIdentity is the identity of a peer authenticated by a verified client certificate,
+// which can be read from the context claims with DecodeClaims
+type CertificateIdentity struct {
+	Subject    string   `json:"subject_dn"`
+	CommonName string   `json:"cn"`
+	DNSNames   []string `json:"dns_names,omitempty"`
+	URIs       []string `json:"uris,omitempty"`
+	SPIFFEID   string   `json:"spiffe_id,omitempty"`
+}
+
+// IdentityFromCertificate returns the identity of the certificate, taking its first spiffe:// URI SAN as SPIFFE ID
+func IdentityFromCertificate(cert *x509.Certificate) CertificateIdentity {
+	identity := CertificateIdentity{
+		Subject:    cert.Subject.String(),
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+		if identity.SPIFFEID == "" && uri.Scheme == "spiffe" {
+			identity.SPIFFEID = uri.String()
+		}
+	}
+	return identity
+}
+
+// IdentityFromConnectionState returns the identity of the verified peer certificate of the TLS connection,
+// or an UnauthorizedErr when the peer did not present a certificate verified by the server
+func IdentityFromConnectionState(state *tls.ConnectionState) (CertificateIdentity, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return CertificateIdentity{}, wrappers.NewUnauthorizedErr(errors.New("verified client certificate is not provided"))
+	}
+	return IdentityFromCertificate(state.VerifiedChains[0][0]), nil
+}
+
+// Names returns the names identifying the peer: its SPIFFE ID, common name and DNS names
+func (i CertificateIdentity) Names() []string {
+	var names []string
+	if i.SPIFFEID != "" {
+		names = append(names, i.SPIFFEID)
+	}
+	if i.CommonName != "" {
+		names = append(names, i.CommonName)
+	}
+	return append(names, i.DNSNames...)
+}
+
+// Claims returns the claims representing the identity, with the SPIFFE ID or, when not present, the common name as sub claim
+func (i CertificateIdentity) Claims() jwt.MapClaims {
+	sub := i.SPIFFEID
+	if sub == "" {
+		sub = i.CommonName
+	}
+
+	claims := jwt.MapClaims{
+		"sub":         sub,
+		"subject_dn":  i.Subject,
+		"cn":          i.CommonName,
+		"peer_names":  toInterfaces(i.Names()),
+		"dns_names":   toInterfaces(i.DNSNames),
+		"uris":        toInterfaces(i.URIs),
+		"auth_method": "mtls",
+	}
+	if i.SPIFFEID != "" {
+		claims["spiffe_id"] = i.SPIFFEID
+	}
+	return claims
+}
+
+// AllowPeers returns a ClaimRule checking that any of the names of a peer authenticated with mTLS matches one of the patterns,
+// which can be exact names or prefixes followed by *, such as spiffe://example.org/ns/prod/*
+func AllowPeers(patterns ...string) ClaimRule {
+	return func(claims jwt.MapClaims) error {
+		for _, name := range claimValues(claims["peer_names"]) {
+			for _, pattern := range patterns {
+				if prefix, ok := strings.CutSuffix(pattern, "*"); (ok && strings.HasPrefix(name, prefix)) || name == pattern {
+					return nil
+				}
+			}
+		}
+		return wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: peer %v not allowed", claims["sub"]))
+	}
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result
+}
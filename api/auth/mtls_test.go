@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/testutils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIdentityFromConnectionState_Ok checks that the identity is extracted from the verified peer certificate
+func TestIdentityFromConnectionState_Ok(t *testing.T) {
+	// Arrange
+	ca := testutils.NewCertificateAuthority(t)
+	cert := ca.IssueCertificate(t, "orders", "orders.internal", "spiffe://example.org/ns/prod/orders")
+	chains, err := cert.Leaf.Verify(x509.VerifyOptions{Roots: ca.Pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.Nil(t, err)
+
+	// Act
+	identity, err := IdentityFromConnectionState(&tls.ConnectionState{VerifiedChains: chains})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "CN=orders,O=Test", identity.Subject)
+	assert.Equal(t, "orders", identity.CommonName)
+	assert.Equal(t, []string{"orders.internal"}, identity.DNSNames)
+	assert.Equal(t, "spiffe://example.org/ns/prod/orders", identity.SPIFFEID)
+	assert.Equal(t, []string{"spiffe://example.org/ns/prod/orders", "orders", "orders.internal"}, identity.Names())
+	assert.Equal(t, "spiffe://example.org/ns/prod/orders", identity.Claims()["sub"])
+}
+
+// TestIdentityFromConnectionState_NotVerified checks that an UnauthorizedErr is returned when there is no verified peer certificate
+func TestIdentityFromConnectionState_NotVerified(t *testing.T) {
+	cases := []struct {
+		name  string
+		state *tls.ConnectionState
+	}{
+		{"No TLS", nil},
+		{"No verified chains", &tls.ConnectionState{}},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := IdentityFromConnectionState(tt.state)
+
+			// Assert
+			assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+			assert.Equal(t, "verified client certificate is not provided", err.Error())
+		})
+	}
+}
+
+// TestAllowPeers checks that the peers are allowed by SPIFFE ID, common name or DNS name, exactly or by prefix
+func TestAllowPeers(t *testing.T) {
+	identity := CertificateIdentity{CommonName: "orders", DNSNames: []string{"orders.internal"}, SPIFFEID: "spiffe://example.org/ns/prod/orders"}
+
+	cases := []struct {
+		name        string
+		patterns    []string
+		expectedErr bool
+	}{
+		{"SPIFFE ID", []string{"spiffe://example.org/ns/prod/orders"}, false},
+		{"SPIFFE prefix", []string{"spiffe://example.org/ns/prod/*"}, false},
+		{"Common name", []string{"payments", "orders"}, false},
+		{"DNS name", []string{"orders.internal"}, false},
+		{"Other namespace", []string{"spiffe://example.org/ns/dev/*"}, true},
+		{"Partial name", []string{"order"}, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := AllowPeers(tt.patterns...)(identity.Claims())
+
+			// Assert
+			if tt.expectedErr {
+				assert.True(t, errors.Is(err, wrappers.UnauthenticatedErr))
+				assert.Equal(t, "insufficient permissions: peer spiffe://example.org/ns/prod/orders not allowed", err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+// TestCertificateIdentity_DecodeClaims checks that the identity can be read back from the context claims
+func TestCertificateIdentity_DecodeClaims(t *testing.T) {
+	// Arrange
+	identity := CertificateIdentity{Subject: "CN=orders", CommonName: "orders", DNSNames: []string{"orders.internal"}, URIs: []string{"spiffe://example.org/orders"}, SPIFFEID: "spiffe://example.org/orders"}
+	ctx := ContextWithClaims(context.Background(), identity.Claims())
+	var decoded CertificateIdentity
+
+	// Act
+	err := DecodeClaims(ctx, &decoded)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, identity, decoded)
+}
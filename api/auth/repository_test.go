@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// memoryRepository is a repository.Repository storing the entities as JSON documents, used to test the repository-backed stores
+type memoryRepository struct {
+	mu        sync.Mutex
+	target    interface{}
+	documents map[string]map[string]interface{}
+	nextID    int
+	failures  error
+}
+
+func newMemoryRepository(target interface{}) *memoryRepository {
+	return &memoryRepository{target: target, documents: make(map[string]map[string]interface{})}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, entity interface{}) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures != nil {
+		return "", r.failures
+	}
+	r.nextID++
+	id := fmt.Sprint(r.nextID)
+	document, err := toDocument(entity)
+	if err != nil {
+		return "", err
+	}
+	document["id"] = id
+	r.documents[id] = document
+	return id, nil
+}
+
+func (r *memoryRepository) Get(ctx context.Context, filter map[string]interface{}, skip, take *int) ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures != nil {
+		return nil, r.failures
+	}
+
+	var result []interface{}
+	for _, document := range r.documents {
+		if !matches(document, filter) {
+			continue
+		}
+		entity, err := r.toEntity(document)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entity)
+		if take != nil && len(result) == *take {
+			break
+		}
+	}
+
+	if len(result) < 1 {
+		return nil, wrappers.NewNonExistentErr(errors.New("no documents"))
+	}
+	return result, nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, ID string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	document, ok := r.documents[ID]
+	if !ok {
+		return nil, wrappers.NewNonExistentErr(errors.New("no documents"))
+	}
+	return r.toEntity(document)
+}
+
+func (r *memoryRepository) Update(ctx context.Context, ID string, entity interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.documents[ID]; !ok {
+		return wrappers.NewNonExistentErr(errors.New("no documents"))
+	}
+	document, err := toDocument(entity)
+	if err != nil {
+		return err
+	}
+	document["id"] = ID
+	r.documents[ID] = document
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, ID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.documents, ID)
+	return nil
+}
+
+func (r *memoryRepository) toEntity(document map[string]interface{}) (interface{}, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	entity := reflect.New(reflect.TypeOf(r.target)).Interface()
+	return entity, json.Unmarshal(data, entity)
+}
+
+func toDocument(entity interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]interface{}
+	return document, json.Unmarshal(data, &document)
+}
+
+func matches(document, filter map[string]interface{}) bool {
+	for key, value := range filter {
+		if fmt.Sprint(document[key]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
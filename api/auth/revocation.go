@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/repository"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+const (
+	revocationKindToken   = "token"
+	revocationKindSubject = "subject"
+)
+
+// RevocationList keeps the revoked tokens by jti and the subjects whose tokens issued before a cutoff are revoked
+type RevocationList interface {
+	// RevokeToken revokes the token with the jti until its expiration
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeSubject revokes all the tokens of the subject issued before the given time
+	RevokeSubject(ctx context.Context, subject string, at time.Time) error
+	// IsRevoked checks whether the token with the jti, subject and issue time has been revoked
+	IsRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error)
+}
+
+// Revocation is the entity stored by the RepositoryRevocationList
+type Revocation struct {
+	ID        string    `json:"id,omitempty" bson:"_id,omitempty"`
+	Kind      string    `json:"kind" bson:"kind"`
+	Key       string    `json:"key" bson:"key"`
+	At        time.Time `json:"at" bson:"at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// MemoryRevocationList is an in-memory RevocationList, suited for tests and single-instance services
+type MemoryRevocationList struct {
+	mu       sync.Mutex
+	tokens   map[string]time.Time
+	subjects map[string]time.Time
+}
+
+// NewMemoryRevocationList creates a new MemoryRevocationList
+func NewMemoryRevocationList() *MemoryRevocationList {
+	return &MemoryRevocationList{
+		tokens:   make(map[string]time.Time),
+		subjects: make(map[string]time.Time),
+	}
+}
+
+// RevokeToken revokes the token with the jti until its expiration, pruning the expired revocations
+func (l *MemoryRevocationList) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range l.tokens {
+		if now.After(exp) {
+			delete(l.tokens, id)
+		}
+	}
+	l.tokens[jti] = expiresAt
+	return nil
+}
+
+// RevokeSubject revokes all the tokens of the subject issued before the given time
+func (l *MemoryRevocationList) RevokeSubject(ctx context.Context, subject string, at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if at.After(l.subjects[subject]) {
+		l.subjects[subject] = at
+	}
+	return nil
+}
+
+// IsRevoked checks whether the token with the jti, subject and issue time has been revoked
+func (l *MemoryRevocationList) IsRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.tokens[jti]; ok && jti != "" {
+		return true, nil
+	}
+	cutoff, ok := l.subjects[subject]
+	return ok && subject != "" && issuedBefore(issuedAt, cutoff), nil
+}
+
+// RepositoryRevocationList is a RevocationList storing Revocation entities through a repository.Repository
+type RepositoryRevocationList struct {
+	repo repository.Repository
+}
+
+// NewRepositoryRevocationList creates a new RepositoryRevocationList with the given repository
+func NewRepositoryRevocationList(repo repository.Repository) *RepositoryRevocationList {
+	return &RepositoryRevocationList{repo: repo}
+}
+
+// RevokeToken revokes the token with the jti until its expiration
+func (l *RepositoryRevocationList) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := l.repo.Create(ctx, Revocation{Kind: revocationKindToken, Key: jti, At: time.Now().UTC(), ExpiresAt: expiresAt.UTC()})
+	return err
+}
+
+// RevokeSubject revokes all the tokens of the subject issued before the given time
+func (l *RepositoryRevocationList) RevokeSubject(ctx context.Context, subject string, at time.Time) error {
+	_, err := l.repo.Create(ctx, Revocation{Kind: revocationKindSubject, Key: subject, At: at.UTC()})
+	return err
+}
+
+// IsRevoked checks whether the token with the jti, subject and issue time has been revoked
+func (l *RepositoryRevocationList) IsRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error) {
+	if jti != "" {
+		revocations, err := l.find(ctx, revocationKindToken, jti)
+		if err != nil || len(revocations) > 0 {
+			return len(revocations) > 0, err
+		}
+	}
+
+	if subject == "" {
+		return false, nil
+	}
+	revocations, err := l.find(ctx, revocationKindSubject, subject)
+	if err != nil {
+		return false, err
+	}
+	for _, revocation := range revocations {
+		if issuedBefore(issuedAt, revocation.At) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (l *RepositoryRevocationList) find(ctx context.Context, kind, key string) ([]*Revocation, error) {
+	entities, err := l.repo.Get(ctx, map[string]interface{}{"kind": kind, "key": key}, nil, nil)
+	if err != nil {
+		if errors.Is(err, wrappers.NonExistentErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	revocations := make([]*Revocation, 0, len(entities))
+	for _, entity := range entities {
+		if revocation, ok := entity.(*Revocation); ok {
+			revocations = append(revocations, revocation)
+		}
+	}
+	return revocations, nil
+}
+
+// issuedBefore compares at the microsecond precision of the iat claim issued by TokenService,
+// so that the tokens issued right after the cutoff are not revoked
+func issuedBefore(issuedAt, cutoff time.Time) bool {
+	return issuedAt.Before(cutoff.Truncate(time.Microsecond))
+}
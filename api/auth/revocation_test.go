@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func revocationLists() map[string]RevocationList {
+	return map[string]RevocationList{
+		"Memory":     NewMemoryRevocationList(),
+		"Repository": NewRepositoryRevocationList(newMemoryRepository(Revocation{})),
+	}
+}
+
+// TestRevocationList_Token checks that only the revoked token is reported as revoked
+func TestRevocationList_Token(t *testing.T) {
+	for name, list := range revocationLists() {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			assert.Nil(t, list.RevokeToken(ctx, "revoked-jti", time.Now().Add(time.Hour)))
+
+			// Act
+			revoked, revokedErr := list.IsRevoked(ctx, "revoked-jti", "test-subject", time.Now())
+			valid, validErr := list.IsRevoked(ctx, "valid-jti", "test-subject", time.Now())
+
+			// Assert
+			assert.Nil(t, revokedErr)
+			assert.True(t, revoked)
+			assert.Nil(t, validErr)
+			assert.False(t, valid)
+		})
+	}
+}
+
+// TestRevocationList_Subject checks that the tokens of the subject issued before the cutoff are revoked and the later ones are not
+func TestRevocationList_Subject(t *testing.T) {
+	for name, list := range revocationLists() {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			cutoff := time.Now()
+			assert.Nil(t, list.RevokeSubject(ctx, "test-subject", cutoff))
+
+			// Act
+			before, _ := list.IsRevoked(ctx, "jti-1", "test-subject", cutoff.Add(-time.Minute))
+			after, _ := list.IsRevoked(ctx, "jti-2", "test-subject", cutoff.Add(time.Minute))
+			other, _ := list.IsRevoked(ctx, "jti-3", "other-subject", cutoff.Add(-time.Minute))
+
+			// Assert
+			assert.True(t, before)
+			assert.False(t, after)
+			assert.False(t, other)
+		})
+	}
+}
+
+// TestMemoryRevocationList_PrunesExpired checks that the expired token revocations are removed on the next revocation
+func TestMemoryRevocationList_PrunesExpired(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	list := NewMemoryRevocationList()
+	list.RevokeToken(ctx, "expired-jti", time.Now().Add(-time.Minute))
+
+	// Act
+	list.RevokeToken(ctx, "valid-jti", time.Now().Add(time.Minute))
+
+	// Assert
+	assert.NotContains(t, list.tokens, "expired-jti")
+	assert.Contains(t, list.tokens, "valid-jti")
+}
+
+// TestRepositoryRevocationList_Error checks that the repository errors are returned
+func TestRepositoryRevocationList_Error(t *testing.T) {
+	// Arrange
+	repo := newMemoryRepository(Revocation{})
+	repo.failures = errors.New("repository error")
+	list := NewRepositoryRevocationList(repo)
+
+	// Act
+	_, err := list.IsRevoked(context.Background(), "jti", "test-subject", time.Now())
+
+	// Assert
+	assert.Equal(t, "repository error", err.Error())
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// ClaimRule checks the values of the claims of a valid token
+type ClaimRule func(claims jwt.MapClaims) error
+
+// ApplyRules applies the rules to the claims, wrapping in an UnauthenticatedErr the errors that are not UnauthorizedErr or UnauthenticatedErr
+func ApplyRules(claims jwt.MapClaims, rules []ClaimRule) error {
+	for _, rule := range rules {
+		err := rule(claims)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, wrappers.UnauthorizedErr) || errors.Is(err, wrappers.UnauthenticatedErr) {
+			return err
+		}
+		return wrappers.NewUnauthenticatedErr(err)
+	}
+	return nil
+}
+
+// RequireClaims returns a ClaimRule checking that the claims contain all the given claim names
+func RequireClaims(names ...string) ClaimRule {
+	return func(claims jwt.MapClaims) error {
+		return CheckRequiredClaims(claims, names)
+	}
+}
+
+// ClaimEquals returns a ClaimRule checking that the claim has the given value
+func ClaimEquals(name, value string) ClaimRule {
+	return func(claims jwt.MapClaims) error {
+		claim, ok := claims[name]
+		if !ok || fmt.Sprint(claim) != value {
+			return wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: claim '%s' does not match", name))
+		}
+		return nil
+	}
+}
+
+// ClaimContainsOneOf returns a ClaimRule checking that the claim, either a single value or a list, contains at least one of the given values
+func ClaimContainsOneOf(name string, values ...string) ClaimRule {
+	return func(claims jwt.MapClaims) error {
+		for _, claim := range claimValues(claims[name]) {
+			for _, value := range values {
+				if claim == value {
+					return nil
+				}
+			}
+		}
+		return wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: claim '%s' does not contain any of %v", name, values))
+	}
+}
+
+// ScopeIncludes returns a ClaimRule checking that the space-delimited scope claim includes all the given scopes
+func ScopeIncludes(scopes ...string) ClaimRule {
+	return func(claims jwt.MapClaims) error {
+		granted := make(map[string]bool)
+		for _, claim := range claimValues(claims["scope"]) {
+			for _, scope := range strings.Fields(claim) {
+				granted[scope] = true
+			}
+		}
+
+		for _, scope := range scopes {
+			if !granted[scope] {
+				return wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: required scope '%s' not granted", scope))
+			}
+		}
+		return nil
+	}
+}
+
+func claimValues(claim interface{}) []string {
+	switch c := claim.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		values := make([]string, 0, len(c))
+		for _, v := range c {
+			values = append(values, fmt.Sprint(v))
+		}
+		return values
+	case []string:
+		return c
+	default:
+		return []string{fmt.Sprint(c)}
+	}
+}
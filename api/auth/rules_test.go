@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClaimRules checks that the built-in claim rules correctly handle all expected scenarios
+func TestClaimRules(t *testing.T) {
+	claims := jwt.MapClaims{
+		"sub":    "test-subject",
+		"admin":  true,
+		"level":  float64(3),
+		"groups": []interface{}{"dev", "ops"},
+		"scope":  "read:users write:users",
+	}
+
+	cases := []struct {
+		name        string
+		rule        ClaimRule
+		expectedErr string
+	}{
+		{"Required claims present", RequireClaims("sub", "groups"), ""},
+		{"Required claim missing", RequireClaims("sub", "tenant"), "insufficient permissions: required claim 'tenant' not found"},
+		{"Claim equals string", ClaimEquals("sub", "test-subject"), ""},
+		{"Claim equals bool", ClaimEquals("admin", "true"), ""},
+		{"Claim equals number", ClaimEquals("level", "3"), ""},
+		{"Claim does not equal", ClaimEquals("sub", "other"), "insufficient permissions: claim 'sub' does not match"},
+		{"Claim equals missing", ClaimEquals("tenant", ""), "insufficient permissions: claim 'tenant' does not match"},
+		{"List contains one of", ClaimContainsOneOf("groups", "admins", "ops"), ""},
+		{"Value contains one of", ClaimContainsOneOf("sub", "test-subject"), ""},
+		{"Contains none of", ClaimContainsOneOf("groups", "admins"), "insufficient permissions: claim 'groups' does not contain any of [admins]"},
+		{"Scopes included", ScopeIncludes("write:users", "read:users"), ""},
+		{"Scope not included", ScopeIncludes("read:users", "delete:users"), "insufficient permissions: required scope 'delete:users' not granted"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := ApplyRules(claims, []ClaimRule{tt.rule})
+
+			// Assert
+			if tt.expectedErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.True(t, errors.Is(err, wrappers.UnauthenticatedErr))
+				assert.Equal(t, tt.expectedErr, err.Error())
+			}
+		})
+	}
+}
+
+// TestApplyRules_CustomRule checks that the plain errors of custom rules are wrapped in an UnauthenticatedErr and typed errors are kept
+func TestApplyRules_CustomRule(t *testing.T) {
+	// Arrange
+	plain := func(claims jwt.MapClaims) error { return errors.New("plain error") }
+	typed := func(claims jwt.MapClaims) error { return wrappers.NewUnauthorizedErr(errors.New("typed error")) }
+
+	// Act
+	plainErr := ApplyRules(jwt.MapClaims{}, []ClaimRule{plain})
+	typedErr := ApplyRules(jwt.MapClaims{}, []ClaimRule{typed})
+
+	// Assert
+	assert.True(t, errors.Is(plainErr, wrappers.UnauthenticatedErr))
+	assert.Equal(t, "plain error", plainErr.Error())
+	assert.True(t, errors.Is(typedErr, wrappers.UnauthorizedErr))
+}
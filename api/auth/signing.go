@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// SigningKey is a KeyProvider that can also sign the tokens it verifies
+type SigningKey interface {
+	KeyProvider
+	SignToken(claims jwt.Claims) (string, error)
+}
+
+type privateKey struct {
+	publicKey
+	key    crypto.Signer
+	method jwt.SigningMethod
+	kid    string
+}
+
+// HMACSigningKey returns a SigningKey signing HS256 tokens with the given shared secret
+func HMACSigningKey(secret []byte) SigningKey {
+	return hmacKey(secret)
+}
+
+// PrivateKey returns a SigningKey signing tokens with the given RSA (RS256), ECDSA (ES256, ES384 or ES512 depending on the curve) or Ed25519 (EdDSA) private key,
+// setting the kid header when not empty and verifying them with the corresponding public key
+func PrivateKey(key crypto.Signer, kid string) (SigningKey, error) {
+	var method jwt.SigningMethod
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			method = jwt.SigningMethodES256
+		case 384:
+			method = jwt.SigningMethodES384
+		case 521:
+			method = jwt.SigningMethodES512
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return privateKey{publicKey: publicKey{key: key.Public()}, key: key, method: method, kid: kid}, nil
+}
+
+// PrivateKeyFromPEM returns a SigningKey for the PEM-encoded RSA, ECDSA or Ed25519 private key,
+// which can be provided as a PKCS8, PKCS1 RSA or SEC1 EC private key
+func PrivateKeyFromPEM(data []byte, kid string) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid private key: not PEM-encoded")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("invalid private key: unsupported PEM block type %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return PrivateKey(signer, kid)
+}
+
+// SignToken signs the claims with the HS256 method
+func (k hmacKey) SignToken(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(k))
+}
+
+// SignToken signs the claims with the method of the private key
+func (k privateKey) SignToken(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.kid != "" {
+		token.Header["kid"] = k.kid
+	}
+	return token.SignedString(k.key)
+}
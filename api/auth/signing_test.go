@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPrivateKeyFromPEM_Ok checks that the tokens signed with PEM-encoded private keys are verified by the same SigningKey
+func TestPrivateKeyFromPEM_Ok(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(edKey)
+	sec1, _ := x509.MarshalECPrivateKey(ecKey)
+
+	cases := []struct {
+		name           string
+		block          *pem.Block
+		expectedMethod string
+	}{
+		{"PKCS1 RSA", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, "RS256"},
+		{"SEC1 EC", &pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}, "ES384"},
+		{"PKCS8 Ed25519", &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}, "EdDSA"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			key, err := PrivateKeyFromPEM(pem.EncodeToMemory(tt.block), "test-kid")
+			assert.Nil(t, err)
+
+			// Act
+			tokenString, err := key.SignToken(jwt.MapClaims{"sub": "test-subject"})
+
+			// Assert
+			assert.Nil(t, err)
+			token, _, _ := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+			assert.Equal(t, tt.expectedMethod, token.Method.Alg())
+			assert.Equal(t, "test-kid", token.Header["kid"])
+			claims, err := NewValidator(key).Validate(context.Background(), tokenString)
+			assert.Nil(t, err)
+			assert.Equal(t, "test-subject", claims["sub"])
+		})
+	}
+}
+
+// TestPrivateKeyFromPEM_Invalid checks that PrivateKeyFromPEM returns an error for data that is not a supported private key
+func TestPrivateKeyFromPEM_Invalid(t *testing.T) {
+	cases := []struct {
+		name        string
+		data        []byte
+		expectedErr string
+	}{
+		{"Not PEM", []byte("invalid"), "invalid private key: not PEM-encoded"},
+		{"Unsupported block", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("key")}), "invalid private key: unsupported PEM block type PUBLIC KEY"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := PrivateKeyFromPEM(tt.data, "")
+
+			// Assert
+			assert.Equal(t, tt.expectedErr, err.Error())
+		})
+	}
+}
+
+// TestHMACSigningKey_Ok checks that the tokens signed with the HMAC signing key are verified with the same secret
+func TestHMACSigningKey_Ok(t *testing.T) {
+	// Arrange
+	key := HMACSigningKey([]byte("test-secret"))
+
+	// Act
+	tokenString, err := key.SignToken(jwt.MapClaims{"sub": "test-subject"})
+
+	// Assert
+	assert.Nil(t, err)
+	claims, err := ParseToken(tokenString, HMACKey([]byte("test-secret")))
+	assert.Nil(t, err)
+	assert.Equal(t, "test-subject", claims["sub"])
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// BearerToken extracts the token from the value of an Authorization header with the format Bearer + {token}
+func BearerToken(authorization string) (string, error) {
+	if authorization == "" {
+		return "", wrappers.NewUnauthorizedErr(errors.New("authorization token is not provided"))
+	}
+
+	bearerToken := strings.Split(authorization, " ")
+	if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
+		return "", wrappers.NewUnauthorizedErr(errors.New("invalid token format, should be Bearer + {token}"))
+	}
+	return bearerToken[1], nil
+}
+
+// ParseToken verifies the signature and the expiration of the JWT token with the key returned by the KeyProvider, and returns its claims
+func ParseToken(tokenString string, keys KeyProvider) (jwt.MapClaims, error) {
+	return NewValidator(keys).Validate(context.Background(), tokenString)
+}
+
+// CheckRequiredClaims checks that the claims contain all the required claim names
+func CheckRequiredClaims(claims jwt.MapClaims, requiredClaims []string) error {
+	for _, requiredClaim := range requiredClaims {
+		if _, ok := claims[requiredClaim]; !ok {
+			return wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: required claim '%s' not found", requiredClaim))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBearerToken_Ok checks that BearerToken returns the token of a valid Authorization header
+func TestBearerToken_Ok(t *testing.T) {
+	// Act
+	token, err := BearerToken("Bearer test-token")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test-token", token)
+}
+
+// TestBearerToken_Missing checks that BearerToken returns an UnauthorizedErr when the header is empty
+func TestBearerToken_Missing(t *testing.T) {
+	// Act
+	_, err := BearerToken("")
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+	assert.Equal(t, "authorization token is not provided", err.Error())
+}
+
+// TestBearerToken_Malformed checks that BearerToken returns an UnauthorizedErr when the header has not the Bearer format
+func TestBearerToken_Malformed(t *testing.T) {
+	// Act
+	_, err := BearerToken("Basic test-token")
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+	assert.Equal(t, "invalid token format, should be Bearer + {token}", err.Error())
+}
+
+// TestParseToken_Expired checks that ParseToken returns an UnauthorizedErr when the token is expired
+func TestParseToken_Expired(t *testing.T) {
+	// Arrange
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": 1}).SignedString([]byte("test-secret"))
+
+	// Act
+	_, err := ParseToken(tokenString, HMACKey([]byte("test-secret")))
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+	assert.Contains(t, err.Error(), "Token is expired")
+}
+
+// TestCheckRequiredClaims_Ok checks that CheckRequiredClaims does not return an error when all the claims are present
+func TestCheckRequiredClaims_Ok(t *testing.T) {
+	// Act
+	err := CheckRequiredClaims(jwt.MapClaims{"role": "admin"}, []string{"role"})
+
+	// Assert
+	assert.Nil(t, err)
+}
+
+// TestCheckRequiredClaims_Missing checks that CheckRequiredClaims returns an UnauthenticatedErr when a claim is missing
+func TestCheckRequiredClaims_Missing(t *testing.T) {
+	// Act
+	err := CheckRequiredClaims(jwt.MapClaims{}, []string{"role"})
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.UnauthenticatedErr))
+	assert.Equal(t, "insufficient permissions: required claim 'role' not found", err.Error())
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type tokenCtxKey string
+
+// TokenKey is the context key of the raw token validated by the HTTP middlewares and the gRPC interceptors
+const TokenKey tokenCtxKey = "token"
+
+// Token is an access token obtained from a TokenSource, with its expiration when known
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// TokenSource provides the access tokens attached to outgoing calls, returning an empty token when it has none for the context
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource
+type TokenSourceFunc func(ctx context.Context) (Token, error)
+
+// Token calls the function
+func (f TokenSourceFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
+
+// ContextWithToken returns a copy of the context holding the raw validated token
+func ContextWithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, TokenKey, token)
+}
+
+// TokenFromContext returns the raw validated token held by the context, if any
+func TokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(TokenKey).(string)
+	return token, ok && token != ""
+}
+
+// ForwardedToken returns a TokenSource forwarding the token validated for the incoming call held by the context
+func ForwardedToken() TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		token, _ := TokenFromContext(ctx)
+		return Token{AccessToken: token}, nil
+	})
+}
+
+// StaticToken returns a TokenSource always providing the same token
+func StaticToken(token string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		return Token{AccessToken: token}, nil
+	})
+}
+
+// CachedTokenSource is a TokenSource reusing the tokens of the underlying source until they are about to expire
+type CachedTokenSource struct {
+	source        TokenSource
+	refreshBefore time.Duration
+	mu            sync.Mutex
+	token         Token
+}
+
+// NewCachedTokenSource creates a new CachedTokenSource that refreshes the token the given time before its expiration
+func NewCachedTokenSource(source TokenSource, refreshBefore time.Duration) *CachedTokenSource {
+	return &CachedTokenSource{source: source, refreshBefore: refreshBefore}
+}
+
+// Token returns the cached token, obtaining a new one from the underlying source when missing or about to expire.
+// Tokens without expiration are cached until Invalidate is called
+func (s *CachedTokenSource) Token(ctx context.Context) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken != "" && (s.token.ExpiresAt.IsZero() || time.Now().Add(s.refreshBefore).Before(s.token.ExpiresAt)) {
+		return s.token, nil
+	}
+
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// Invalidate discards the cached token, forcing the next call to obtain a new one
+func (s *CachedTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = Token{}
+}
+
+// TokenSource returns a TokenSource issuing access tokens for the subject with the given claims, to be wrapped in a CachedTokenSource
+func (s *TokenService) TokenSource(subject string, claims map[string]interface{}) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		accessToken, expiresAt, err := s.IssueAccessToken(subject, claims)
+		if err != nil {
+			return Token{}, err
+		}
+		return Token{AccessToken: accessToken, ExpiresAt: expiresAt}, nil
+	})
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countingTokenSource(calls *int, ttl time.Duration) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		*calls++
+		token := Token{AccessToken: fmt.Sprintf("token-%d", *calls)}
+		if ttl != 0 {
+			token.ExpiresAt = time.Now().Add(ttl)
+		}
+		return token, nil
+	})
+}
+
+// TestForwardedToken checks that the forwarded token is the one held by the context, and empty when there is none
+func TestForwardedToken(t *testing.T) {
+	// Arrange
+	ctx := ContextWithToken(context.Background(), "incoming-token")
+
+	// Act
+	forwarded, forwardedErr := ForwardedToken().Token(ctx)
+	missing, missingErr := ForwardedToken().Token(context.Background())
+
+	// Assert
+	assert.Nil(t, forwardedErr)
+	assert.Equal(t, "incoming-token", forwarded.AccessToken)
+	assert.Nil(t, missingErr)
+	assert.Equal(t, "", missing.AccessToken)
+}
+
+// TestCachedTokenSource_Reuse checks that the token is reused while it is not about to expire
+func TestCachedTokenSource_Reuse(t *testing.T) {
+	// Arrange
+	var calls int
+	source := NewCachedTokenSource(countingTokenSource(&calls, time.Hour), time.Minute)
+
+	// Act
+	first, _ := source.Token(context.Background())
+	second, _ := source.Token(context.Background())
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, first, second)
+}
+
+// TestCachedTokenSource_Refresh checks that a new token is obtained when the cached one is about to expire or is invalidated
+func TestCachedTokenSource_Refresh(t *testing.T) {
+	// Arrange
+	var calls int
+	source := NewCachedTokenSource(countingTokenSource(&calls, 30*time.Second), time.Minute)
+
+	// Act
+	first, _ := source.Token(context.Background())
+	second, _ := source.Token(context.Background())
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.NotEqual(t, first.AccessToken, second.AccessToken)
+}
+
+// TestCachedTokenSource_Invalidate checks that a token without expiration is cached until invalidated
+func TestCachedTokenSource_Invalidate(t *testing.T) {
+	// Arrange
+	var calls int
+	source := NewCachedTokenSource(countingTokenSource(&calls, 0), time.Minute)
+	source.Token(context.Background())
+	source.Token(context.Background())
+
+	// Act
+	source.Invalidate()
+	source.Token(context.Background())
+
+	// Assert
+	assert.Equal(t, 2, calls)
+}
+
+// TestCachedTokenSource_Error checks that the errors of the underlying source are returned and not cached
+func TestCachedTokenSource_Error(t *testing.T) {
+	// Arrange
+	var calls int
+	source := NewCachedTokenSource(TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		calls++
+		return Token{}, errors.New("source error")
+	}), time.Minute)
+
+	// Act
+	_, err := source.Token(context.Background())
+	source.Token(context.Background())
+
+	// Assert
+	assert.Equal(t, "source error", err.Error())
+	assert.Equal(t, 2, calls)
+}
+
+// TestTokenService_TokenSource checks that the token source of the service issues valid access tokens for the subject
+func TestTokenService_TokenSource(t *testing.T) {
+	// Arrange
+	service := NewTokenService(HMACSigningKey([]byte("test-secret")), nil, nil)
+
+	// Act
+	token, err := service.TokenSource("test-service", map[string]interface{}{"scope": "internal"}).Token(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(defaultAccessTokenTTL), token.ExpiresAt, time.Second)
+	claims, err := service.Validator().Validate(context.Background(), token.AccessToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-service", claims["sub"])
+	assert.Equal(t, "internal", claims["scope"])
+}
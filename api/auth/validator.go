@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// TokenValidator validates a token and returns its claims
+type TokenValidator interface {
+	Validate(ctx context.Context, tokenString string) (jwt.MapClaims, error)
+}
+
+// Validator is a TokenValidator that verifies the signature of the JWT tokens with a KeyProvider,
+// their registered claims (exp, nbf, iat, iss and aud) and the values of the claims with the rules
+type Validator struct {
+	// Keys resolves the key used to verify the signature of the tokens
+	Keys KeyProvider
+	// Issuer is the expected iss claim, not checked when empty
+	Issuer string
+	// Audience is the audience that must be included in the aud claim, not checked when empty
+	Audience string
+	// Leeway is the clock skew tolerated when checking the exp, nbf and iat claims
+	Leeway time.Duration
+	// Rules are the checks applied to the claims once the token is valid
+	Rules []ClaimRule
+	// Revocations is the list checked for revoked tokens and subjects, not checked when nil
+	Revocations RevocationList
+}
+
+// NewValidator creates a new Validator with the given KeyProvider and claim rules
+func NewValidator(keys KeyProvider, rules ...ClaimRule) *Validator {
+	return &Validator{
+		Keys:  keys,
+		Rules: rules,
+	}
+}
+
+// Validate verifies the token and its claims, returning an UnauthorizedErr when the token is not valid
+// and an UnauthenticatedErr when its claims do not satisfy the rules
+func (v *Validator) Validate(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	keyFunc := v.Keys.VerificationKey
+	if keys, ok := v.Keys.(ContextKeyProvider); ok {
+		keyFunc = func(token *jwt.Token) (interface{}, error) {
+			return keys.VerificationKeyContext(ctx, token)
+		}
+	}
+	if _, err := parser.ParseWithClaims(tokenString, claims, keyFunc); err != nil {
+		return nil, wrappers.NewUnauthorizedErr(fmt.Errorf("invalid token: %v", err))
+	}
+
+	if err := v.verifyRegisteredClaims(claims); err != nil {
+		return nil, wrappers.NewUnauthorizedErr(fmt.Errorf("invalid token: %v", err))
+	}
+
+	if err := v.verifyRevocation(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	if err := ApplyRules(claims, v.Rules); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Validator) verifyRegisteredClaims(claims jwt.MapClaims) error {
+	now := jwt.TimeFunc()
+	if !claims.VerifyExpiresAt(now.Add(-v.Leeway).Unix(), false) {
+		return errors.New("Token is expired")
+	}
+	if !claims.VerifyNotBefore(now.Add(v.Leeway).Unix(), false) {
+		return errors.New("Token is not valid yet")
+	}
+	if !claims.VerifyIssuedAt(now.Add(v.Leeway).Unix(), false) {
+		return errors.New("Token used before issued")
+	}
+	if v.Issuer != "" && !claims.VerifyIssuer(v.Issuer, true) {
+		return errors.New("Token has invalid issuer")
+	}
+	if v.Audience != "" && !claims.VerifyAudience(v.Audience, true) {
+		return errors.New("Token has invalid audience")
+	}
+	return nil
+}
+
+func (v *Validator) verifyRevocation(ctx context.Context, claims jwt.MapClaims) error {
+	if v.Revocations == nil {
+		return nil
+	}
+
+	jti, _ := claims["jti"].(string)
+	subject, _ := claims["sub"].(string)
+	var issuedAt time.Time
+	if iat, ok := claims["iat"].(float64); ok {
+		issuedAt = time.UnixMicro(int64(math.Round(iat * 1e6)))
+	}
+
+	revoked, err := v.Revocations.IsRevoked(ctx, jti, subject, issuedAt)
+	if err != nil {
+		return wrappers.NewServiceUnavailableErr(fmt.Errorf("failed to check token revocation: %w", err))
+	}
+	if revoked {
+		return wrappers.NewUnauthorizedErr(errors.New("invalid token: Token has been revoked"))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func signClaims(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenString
+}
+
+// TestValidator_RegisteredClaims checks that the Validator correctly validates the registered claims in all expected scenarios
+func TestValidator_RegisteredClaims(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name        string
+		claims      jwt.MapClaims
+		expectedErr string
+	}{
+		{"Valid claims", jwt.MapClaims{"iss": "test-issuer", "aud": "test-audience", "exp": now.Add(time.Minute).Unix()}, ""},
+		{"Audience list", jwt.MapClaims{"iss": "test-issuer", "aud": []string{"other", "test-audience"}}, ""},
+		{"Expired within leeway", jwt.MapClaims{"iss": "test-issuer", "aud": "test-audience", "exp": now.Add(-10 * time.Second).Unix()}, ""},
+		{"Not before within leeway", jwt.MapClaims{"iss": "test-issuer", "aud": "test-audience", "nbf": now.Add(10 * time.Second).Unix()}, ""},
+		{"Expired", jwt.MapClaims{"iss": "test-issuer", "aud": "test-audience", "exp": now.Add(-time.Minute).Unix()}, "invalid token: Token is expired"},
+		{"Not valid yet", jwt.MapClaims{"iss": "test-issuer", "aud": "test-audience", "nbf": now.Add(time.Minute).Unix()}, "invalid token: Token is not valid yet"},
+		{"Issued in the future", jwt.MapClaims{"iss": "test-issuer", "aud": "test-audience", "iat": now.Add(time.Minute).Unix()}, "invalid token: Token used before issued"},
+		{"Invalid issuer", jwt.MapClaims{"iss": "other", "aud": "test-audience"}, "invalid token: Token has invalid issuer"},
+		{"Missing issuer", jwt.MapClaims{"aud": "test-audience"}, "invalid token: Token has invalid issuer"},
+		{"Invalid audience", jwt.MapClaims{"iss": "test-issuer", "aud": "other"}, "invalid token: Token has invalid audience"},
+	}
+
+	validator := NewValidator(HMACKey([]byte("test-secret")))
+	validator.Issuer = "test-issuer"
+	validator.Audience = "test-audience"
+	validator.Leeway = 30 * time.Second
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			claims, err := validator.Validate(context.Background(), signClaims(t, "test-secret", tt.claims))
+
+			// Assert
+			if tt.expectedErr == "" {
+				assert.Nil(t, err)
+				assert.NotNil(t, claims)
+			} else {
+				assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+				assert.Equal(t, tt.expectedErr, err.Error())
+			}
+		})
+	}
+}
+
+// TestValidator_InvalidSignature checks that the Validator returns an UnauthorizedErr when the signature is not valid
+func TestValidator_InvalidSignature(t *testing.T) {
+	// Arrange
+	validator := NewValidator(HMACKey([]byte("test-secret")))
+
+	// Act
+	_, err := validator.Validate(context.Background(), signClaims(t, "other-secret", jwt.MapClaims{}))
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.UnauthorizedErr))
+	assert.Equal(t, "invalid token: signature is invalid", err.Error())
+}
+
+// TestValidator_Rules checks that the Validator returns an UnauthenticatedErr when the claims do not satisfy the rules
+func TestValidator_Rules(t *testing.T) {
+	// Arrange
+	validator := NewValidator(HMACKey([]byte("test-secret")), ClaimEquals("tenant", "test-tenant"))
+
+	// Act
+	_, err := validator.Validate(context.Background(), signClaims(t, "test-secret", jwt.MapClaims{"tenant": "other"}))
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.UnauthenticatedErr))
+	assert.Equal(t, "insufficient permissions: claim 'tenant' does not match", err.Error())
+}
+
+// TestValidator_RevocationError checks that the Validator returns a ServiceUnavailableErr when the revocation list cannot be checked
+func TestValidator_RevocationError(t *testing.T) {
+	// Arrange
+	repo := newMemoryRepository(Revocation{})
+	repo.failures = errors.New("repository error")
+	validator := NewValidator(HMACKey([]byte("test-secret")))
+	validator.Revocations = NewRepositoryRevocationList(repo)
+
+	// Act
+	_, err := validator.Validate(context.Background(), signClaims(t, "test-secret", jwt.MapClaims{"jti": "test-jti"}))
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.ServiceUnavailableErr))
+	assert.Equal(t, "failed to check token revocation: repository error", err.Error())
+}
@@ -0,0 +1,50 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultAPIKeyMetadataKey is the metadata key read by the API key interceptors when none is configured
+const DefaultAPIKeyMetadataKey = "x-api-key"
+
+// UnaryAPIKey is a configurable gRPC unary interceptor that validates the API key of the metadata against the store and applies the method policy,
+// whose rules can check the scopes of the key with auth.ScopeIncludes, for the incomming call
+func UnaryAPIKey(store auth.APIKeyStore, metadataKey string, methods []MethodPolicy, opts ...JWTOption) grpc.UnaryServerInterceptor {
+	validator := auth.NewAPIKeyValidator(store)
+	return unaryAuth(methods, opts, func(ctx context.Context, policy MethodPolicy) (context.Context, error) {
+		return apiKeyValidator(ctx, validator, metadataKey, policy)
+	})
+}
+
+// StreamAPIKey is a configurable gRPC stream interceptor that validates the API key of the metadata against the store and applies the method policy,
+// whose rules can check the scopes of the key with auth.ScopeIncludes, for the incomming call
+func StreamAPIKey(store auth.APIKeyStore, metadataKey string, methods []MethodPolicy, opts ...JWTOption) grpc.StreamServerInterceptor {
+	validator := auth.NewAPIKeyValidator(store)
+	return streamAuth(methods, opts, func(ctx context.Context, policy MethodPolicy) (context.Context, error) {
+		return apiKeyValidator(ctx, validator, metadataKey, policy)
+	})
+}
+
+func apiKeyValidator(ctx context.Context, validator auth.TokenValidator, metadataKey string, policy MethodPolicy) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(errors.New("metadata is not provided")))
+	}
+
+	if metadataKey == "" {
+		metadataKey = DefaultAPIKeyMetadataKey
+	}
+	var key string
+	if keys := md.Get(metadataKey); len(keys) > 0 {
+		key = keys[0]
+	}
+
+	return authorize(ctx, validator, key, policy)
+}
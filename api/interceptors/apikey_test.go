@@ -0,0 +1,82 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var apiKeyStore = auth.NewStaticAPIKeyStore(map[string]auth.Principal{
+	"reader-key": {ID: "reader", Scopes: []string{"orders:read"}},
+	"writer-key": {ID: "writer", Scopes: []string{"orders:read", "orders:write"}},
+})
+
+var apiKeyMethods = []MethodPolicy{
+	{MethodName: "/pkg.Orders/Get", Rules: []auth.ClaimRule{auth.ScopeIncludes("orders:read")}},
+	{MethodName: "/pkg.Orders/*", Rules: []auth.ClaimRule{auth.ScopeIncludes("orders:write")}},
+}
+
+// TestUnaryAPIKey checks that the unary API key interceptor correctly handles all expected scenarios
+func TestUnaryAPIKey(t *testing.T) {
+	cases := []struct {
+		name            string
+		method          string
+		md              metadata.MD
+		expectedCode    codes.Code
+		expectedSubject string
+	}{
+		{"Valid key with scope", "/pkg.Orders/Get", metadata.Pairs(DefaultAPIKeyMetadataKey, "reader-key"), codes.OK, "reader"},
+		{"Valid key without scope", "/pkg.Orders/Create", metadata.Pairs(DefaultAPIKeyMetadataKey, "reader-key"), codes.PermissionDenied, ""},
+		{"Invalid key", "/pkg.Orders/Get", metadata.Pairs(DefaultAPIKeyMetadataKey, "unknown-key"), codes.Unauthenticated, ""},
+		{"Missing key", "/pkg.Orders/Get", metadata.MD{}, codes.Unauthenticated, ""},
+		{"Unprotected method", "/pkg.Other/Get", metadata.MD{}, codes.OK, ""},
+	}
+
+	interceptor := UnaryAPIKey(apiKeyStore, "", apiKeyMethods)
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			var subject string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				subject = auth.Subject(ctx)
+				return nil, nil
+			}
+
+			// Act
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedSubject, subject)
+		})
+	}
+}
+
+// TestStreamAPIKey checks that the stream API key interceptor reads the key from the configured metadata key
+func TestStreamAPIKey(t *testing.T) {
+	// Arrange
+	interceptor := StreamAPIKey(apiKeyStore, "X-Partner-Key", apiKeyMethods)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-partner-key", "writer-key"))
+
+	var subject string
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		subject = auth.Subject(ss.Context())
+		return nil
+	}
+
+	// Act
+	err := interceptor(nil, wrappers.NewGRPCServerStream(ctx), &grpc.StreamServerInfo{FullMethod: "/pkg.Orders/Watch"}, handler)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "writer", subject)
+}
@@ -0,0 +1,53 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientToken is a gRPC unary client interceptor that attaches as bearer token to the outgoing metadata the first token provided by the sources,
+// such as auth.ForwardedToken to propagate the token of the incoming call. Calls already carrying an authorization metadata are left untouched
+func UnaryClientToken(sources ...auth.TokenSource) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		newCtx, err := withBearerToken(ctx, sources)
+		if err != nil {
+			return err
+		}
+		return invoker(newCtx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientToken is a gRPC stream client interceptor that attaches as bearer token to the outgoing metadata the first token provided by the sources,
+// such as auth.ForwardedToken to propagate the token of the incoming call. Calls already carrying an authorization metadata are left untouched
+func StreamClientToken(sources ...auth.TokenSource) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		newCtx, err := withBearerToken(ctx, sources)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(newCtx, desc, cc, method, opts...)
+	}
+}
+
+func withBearerToken(ctx context.Context, sources []auth.TokenSource) (context.Context, error) {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		return ctx, nil
+	}
+
+	for _, source := range sources {
+		token, err := source.Token(ctx)
+		if err != nil {
+			return nil, utils.ToGRPC(wrappers.NewServiceUnavailableErr(fmt.Errorf("failed to obtain token: %w", err)))
+		}
+		if token.AccessToken != "" {
+			return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token.AccessToken), nil
+		}
+	}
+	return ctx, nil
+}
@@ -0,0 +1,96 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestUnaryClientToken checks that the unary client interceptor correctly handles all expected scenarios
+func TestUnaryClientToken(t *testing.T) {
+	failing := auth.TokenSourceFunc(func(ctx context.Context) (auth.Token, error) { return auth.Token{}, errors.New("source error") })
+
+	cases := []struct {
+		name         string
+		ctx          context.Context
+		sources      []auth.TokenSource
+		expectedAuth []string
+		expectedCode codes.Code
+	}{
+		{"Forwarded token", auth.ContextWithToken(context.Background(), "incoming-token"), []auth.TokenSource{auth.ForwardedToken(), auth.StaticToken("service-token")}, []string{"Bearer incoming-token"}, codes.OK},
+		{"Fallback source", context.Background(), []auth.TokenSource{auth.ForwardedToken(), auth.StaticToken("service-token")}, []string{"Bearer service-token"}, codes.OK},
+		{"No token", context.Background(), []auth.TokenSource{auth.ForwardedToken()}, nil, codes.OK},
+		{"Existing authorization", metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer explicit-token"), []auth.TokenSource{auth.StaticToken("service-token")}, []string{"Bearer explicit-token"}, codes.OK},
+		{"Source error", context.Background(), []auth.TokenSource{failing}, nil, codes.Unavailable},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var authorization []string
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				authorization = md.Get("authorization")
+				return nil
+			}
+
+			// Act
+			err := UnaryClientToken(tt.sources...)(tt.ctx, "/pkg.Service/Get", nil, nil, nil, invoker)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedAuth, authorization)
+		})
+	}
+}
+
+// TestStreamClientToken checks that the stream client interceptor attaches the token to the outgoing metadata
+func TestStreamClientToken(t *testing.T) {
+	// Arrange
+	var authorization []string
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		authorization = md.Get("authorization")
+		return nil, nil
+	}
+
+	// Act
+	_, err := StreamClientToken(auth.StaticToken("service-token"))(context.Background(), &grpc.StreamDesc{}, nil, "/pkg.Service/Watch", streamer)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Bearer service-token"}, authorization)
+}
+
+// TestUnaryClientToken_Propagation checks that the token validated by the server interceptor is propagated to the downstream calls
+func TestUnaryClientToken_Propagation(t *testing.T) {
+	// Arrange
+	method := "/pkg.Service/Get"
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "test-subject"}).SignedString([]byte("test-secret"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tokenString))
+
+	var authorization []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		authorization = md.Get("authorization")
+		return nil
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, UnaryClientToken(auth.ForwardedToken())(ctx, "/downstream.Service/Get", nil, nil, nil, invoker)
+	}
+
+	// Act
+	_, err := UnaryJWT("test-secret", []MethodPolicy{{MethodName: method}})(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Bearer " + tokenString}, authorization)
+}
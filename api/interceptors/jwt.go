@@ -4,33 +4,94 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
 	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
 	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
-type claimsCtxKey string
-
-const ClaimsKey claimsCtxKey = "claims"
+// ClaimsKey is the context key of the claims of the authenticated caller
+//
+// Deprecated: use auth.ClaimsFromContext to read the claims regardless of the transport
+const ClaimsKey = auth.ClaimsKey
 
+// MethodPolicy defines the authorization rules of the methods matching its name, which can be a full method name such as /pkg.Service/Method,
+// a service such as /pkg.Service/* or any prefix followed by *
 type MethodPolicy struct {
 	MethodName     string
+	Public         bool
 	RequiredClaims []string
+	Rules          []auth.ClaimRule
+}
+
+// JWTOption configures the JWT interceptors
+type JWTOption func(*jwtOptions)
+
+type jwtOptions struct {
+	defaultDeny bool
+}
+
+// WithDefaultDeny rejects the calls to the methods not matching any policy instead of leaving them unprotected
+func WithDefaultDeny() JWTOption {
+	return func(o *jwtOptions) {
+		o.defaultDeny = true
+	}
 }
 
-// UnaryJWT is a configurable gRPC unary interceptor that validates the JWT tokens and its claims for the incomming call
-func UnaryJWT(jwtSecret string, methods []MethodPolicy) grpc.UnaryServerInterceptor {
+// UnaryJWT is a configurable gRPC unary interceptor that validates the JWT tokens signed with the HMAC secret and its claims for the incomming call
+func UnaryJWT(jwtSecret string, methods []MethodPolicy, opts ...JWTOption) grpc.UnaryServerInterceptor {
+	return UnaryJWTWithKeys(auth.HMACKey([]byte(jwtSecret)), methods, opts...)
+}
+
+// UnaryJWTWithKeys is a configurable gRPC unary interceptor that validates the JWT tokens with the keys of the KeyProvider and its claims for the incomming call
+func UnaryJWTWithKeys(keys auth.KeyProvider, methods []MethodPolicy, opts ...JWTOption) grpc.UnaryServerInterceptor {
+	return UnaryJWTWithValidator(auth.NewValidator(keys), methods, opts...)
+}
+
+// UnaryJWTWithValidator is a configurable gRPC unary interceptor that validates the tokens with the TokenValidator and applies the method policy for the incomming call
+func UnaryJWTWithValidator(validator auth.TokenValidator, methods []MethodPolicy, opts ...JWTOption) grpc.UnaryServerInterceptor {
+	return unaryAuth(methods, opts, func(ctx context.Context, policy MethodPolicy) (context.Context, error) {
+		return jwtValidator(ctx, validator, policy)
+	})
+}
+
+// StreamJWT is a configurable gRPC stream interceptor that validates the JWT tokens signed with the HMAC secret and its claims for the incomming call
+func StreamJWT(jwtSecret string, methods []MethodPolicy, opts ...JWTOption) grpc.StreamServerInterceptor {
+	return StreamJWTWithKeys(auth.HMACKey([]byte(jwtSecret)), methods, opts...)
+}
+
+// StreamJWTWithKeys is a configurable gRPC stream interceptor that validates the JWT tokens with the keys of the KeyProvider and its claims for the incomming call
+func StreamJWTWithKeys(keys auth.KeyProvider, methods []MethodPolicy, opts ...JWTOption) grpc.StreamServerInterceptor {
+	return StreamJWTWithValidator(auth.NewValidator(keys), methods, opts...)
+}
+
+// StreamJWTWithValidator is a configurable gRPC stream interceptor that validates the tokens with the TokenValidator and applies the method policy for the incomming call
+func StreamJWTWithValidator(validator auth.TokenValidator, methods []MethodPolicy, opts ...JWTOption) grpc.StreamServerInterceptor {
+	return streamAuth(methods, opts, func(ctx context.Context, policy MethodPolicy) (context.Context, error) {
+		return jwtValidator(ctx, validator, policy)
+	})
+}
+
+type authenticateFunc func(ctx context.Context, policy MethodPolicy) (context.Context, error)
+
+func unaryAuth(methods []MethodPolicy, opts []JWTOption, authenticate authenticateFunc) grpc.UnaryServerInterceptor {
+	options := newJWTOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		policy, isProtected := findMethodPolicy(methods, info.FullMethod)
-		if !isProtected {
+		policy, found := findMethodPolicy(methods, info.FullMethod)
+		if !found && options.defaultDeny {
+			return nil, deniedMethodErr(info.FullMethod)
+		}
+		if !found || policy.Public {
 			return handler(ctx, req)
 		}
 
-		newCtx, err := jwtValidator(ctx, jwtSecret, policy.RequiredClaims)
+		newCtx, err := authenticate(ctx, policy)
 		if err != nil {
 			return nil, err
 		}
@@ -39,16 +100,19 @@ func UnaryJWT(jwtSecret string, methods []MethodPolicy) grpc.UnaryServerIntercep
 	}
 }
 
-// StreamJWT is a configurable gRPC stream interceptor that validates the JWT tokens and its claims for the incomming call
-func StreamJWT(jwtSecret string, methods []MethodPolicy) grpc.StreamServerInterceptor {
+func streamAuth(methods []MethodPolicy, opts []JWTOption, authenticate authenticateFunc) grpc.StreamServerInterceptor {
+	options := newJWTOptions(opts)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		policy, isProtected := findMethodPolicy(methods, info.FullMethod)
-		if !isProtected {
+		policy, found := findMethodPolicy(methods, info.FullMethod)
+		if !found && options.defaultDeny {
+			return deniedMethodErr(info.FullMethod)
+		}
+		if !found || policy.Public {
 			return handler(srv, ss)
 		}
 
 		ctx := ss.Context()
-		newCtx, err := jwtValidator(ctx, jwtSecret, policy.RequiredClaims)
+		newCtx, err := authenticate(ctx, policy)
 		if err != nil {
 			return err
 		}
@@ -59,50 +123,102 @@ func StreamJWT(jwtSecret string, methods []MethodPolicy) grpc.StreamServerInterc
 	}
 }
 
+// ValidateMethodPolicies logs a warning for each policy not matching any method registered on the server, and returns their names
+func ValidateMethodPolicies(server *grpc.Server, methods []MethodPolicy) []string {
+	var registered []string
+	for service, info := range server.GetServiceInfo() {
+		for _, method := range info.Methods {
+			registered = append(registered, "/"+service+"/"+method.Name)
+		}
+	}
+
+	var unmatched []string
+	for _, policy := range methods {
+		var matched bool
+		for _, fullMethod := range registered {
+			if _, ok := matchMethodPolicy(policy, fullMethod); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, policy.MethodName)
+			observability.Logger().Printf("WARNING: method policy %s does not match any method registered on the gRPC server", policy.MethodName)
+		}
+	}
+	return unmatched
+}
+
+func newJWTOptions(opts []JWTOption) jwtOptions {
+	var options jwtOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// findMethodPolicy returns the policy matching the method, preferring exact matches and then the longest prefix
 func findMethodPolicy(methods []MethodPolicy, fullMethod string) (MethodPolicy, bool) {
+	var found MethodPolicy
+	bestScore := -1
 	for _, policy := range methods {
-		if policy.MethodName == fullMethod {
-			return policy, true
+		if score, ok := matchMethodPolicy(policy, fullMethod); ok && score > bestScore {
+			found, bestScore = policy, score
 		}
 	}
-	return MethodPolicy{}, false
+	return found, bestScore >= 0
 }
 
-func jwtValidator(ctx context.Context, jwtSecret string, requiredClaims []string) (context.Context, error) {
+func matchMethodPolicy(policy MethodPolicy, fullMethod string) (int, bool) {
+	if policy.MethodName == fullMethod {
+		return math.MaxInt, true
+	}
+	if prefix, ok := strings.CutSuffix(policy.MethodName, "*"); ok && strings.HasPrefix(fullMethod, prefix) {
+		return len(prefix), true
+	}
+	return 0, false
+}
+
+func deniedMethodErr(fullMethod string) error {
+	return utils.ToGRPC(wrappers.NewUnauthenticatedErr(fmt.Errorf("access to method %s is denied", fullMethod)))
+}
+
+func jwtValidator(ctx context.Context, validator auth.TokenValidator, policy MethodPolicy) (context.Context, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(errors.New("metadata is not provided")))
 	}
 
-	tokens := md.Get("authorization")
-	if len(tokens) == 0 {
-		return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(errors.New("authorization token is not provided")))
+	var authorization string
+	if tokens := md.Get("authorization"); len(tokens) > 0 {
+		authorization = tokens[0]
 	}
-	authorization := tokens[0]
 
-	bearerToken := strings.Split(authorization, " ")
-	if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-		return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(errors.New("invalid token format, should be Bearer + {token}")))
+	tokenString, err := auth.BearerToken(authorization)
+	if err != nil {
+		return nil, utils.ToGRPC(err)
 	}
-	tokenString := bearerToken[1]
 
-	claims := jwt.MapClaims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(errors.New("signin method not valid")))
-		}
-		return []byte(jwtSecret), nil
-	})
+	return authorize(auth.ContextWithToken(ctx, tokenString), validator, tokenString, policy)
+}
 
-	if err != nil || !token.Valid {
-		return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(fmt.Errorf("invalid token: %v", err)))
+func authorize(ctx context.Context, validator auth.TokenValidator, credential string, policy MethodPolicy) (context.Context, error) {
+	claims, err := validator.Validate(ctx, credential)
+	if err != nil {
+		return nil, utils.ToGRPC(err)
 	}
 
-	for _, requiredClaim := range requiredClaims {
-		if _, ok := claims[requiredClaim]; !ok {
-			return nil, utils.ToGRPC(wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: required claim '%s' not found", requiredClaim)))
-		}
+	return applyPolicy(ctx, claims, policy)
+}
+
+func applyPolicy(ctx context.Context, claims jwt.MapClaims, policy MethodPolicy) (context.Context, error) {
+	if err := auth.CheckRequiredClaims(claims, policy.RequiredClaims); err != nil {
+		return nil, utils.ToGRPC(err)
+	}
+
+	if err := auth.ApplyRules(claims, policy.Rules); err != nil {
+		return nil, utils.ToGRPC(err)
 	}
 
-	return context.WithValue(ctx, ClaimsKey, claims), nil
+	return auth.ContextWithClaims(ctx, claims), nil
 }
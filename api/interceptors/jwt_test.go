@@ -2,9 +2,14 @@ package interceptors
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
 	"strings"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
 	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -85,6 +90,107 @@ func TestStreamJWT(t *testing.T) {
 	}
 }
 
+// TestUnaryJWTWithKeys_PublicKey checks that the unary interceptor validates tokens signed with an asymmetric key using its public key
+func TestUnaryJWTWithKeys_PublicKey(t *testing.T) {
+	// Arrange
+	method := "/TestService/TestMethod"
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	keys, _ := auth.PublicKey(publicKey)
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{"sub": "test-subject"}).SignedString(privateKey)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tokenString))
+
+	interceptor := UnaryJWTWithKeys(keys, []MethodPolicy{{MethodName: method}})
+
+	var claims jwt.MapClaims
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims = ctx.Value(ClaimsKey).(jwt.MapClaims)
+		return nil, nil
+	}
+
+	// Act
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test-subject", claims["sub"])
+}
+
+// TestStreamJWTWithKeys_PublicKey checks that the stream interceptor validates tokens signed with an asymmetric key using its public key
+func TestStreamJWTWithKeys_PublicKey(t *testing.T) {
+	// Arrange
+	method := "/TestService/TestStreamMethod"
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys, _ := auth.PublicKey(&privateKey.PublicKey)
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "test-subject"}).SignedString(privateKey)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tokenString))
+
+	interceptor := StreamJWTWithKeys(keys, []MethodPolicy{{MethodName: method}})
+
+	var claims jwt.MapClaims
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		claims = ss.Context().Value(ClaimsKey).(jwt.MapClaims)
+		return nil
+	}
+
+	// Act
+	err := interceptor(nil, wrappers.NewGRPCServerStream(ctx), &grpc.StreamServerInfo{FullMethod: method}, handler)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test-subject", claims["sub"])
+}
+
+// TestUnaryJWTWithValidator_Rules checks that the unary interceptor validates the registered claims and applies the rules of the method policy
+func TestUnaryJWTWithValidator_Rules(t *testing.T) {
+	method := "/TestService/TestMethod"
+	validator := auth.NewValidator(auth.HMACKey([]byte("test-secret")))
+	validator.Audience = "test-audience"
+	interceptor := UnaryJWTWithValidator(validator, []MethodPolicy{{MethodName: method, Rules: []auth.ClaimRule{auth.ClaimContainsOneOf("roles", "admin")}}})
+
+	cases := []struct {
+		name         string
+		claims       jwt.MapClaims
+		expectedCode codes.Code
+	}{
+		{"Valid audience and role", jwt.MapClaims{"aud": "test-audience", "roles": []string{"user", "admin"}}, codes.OK},
+		{"Invalid audience", jwt.MapClaims{"aud": "other", "roles": []string{"admin"}}, codes.Unauthenticated},
+		{"Missing role", jwt.MapClaims{"aud": "test-audience", "roles": []string{"user"}}, codes.PermissionDenied},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, tt.claims).SignedString([]byte("test-secret"))
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tokenString))
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+			// Act
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+}
+
+// TestStreamJWTWithValidator_Rules checks that the stream interceptor applies the rules of the method policy
+func TestStreamJWTWithValidator_Rules(t *testing.T) {
+	// Arrange
+	method := "/TestService/TestStreamMethod"
+	validator := auth.NewValidator(auth.HMACKey([]byte("test-secret")))
+	interceptor := StreamJWTWithValidator(validator, []MethodPolicy{{MethodName: method, Rules: []auth.ClaimRule{auth.ScopeIncludes("stream")}}})
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"scope": "read"}).SignedString([]byte("test-secret"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tokenString))
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+
+	// Act
+	err := interceptor(nil, wrappers.NewGRPCServerStream(ctx), &grpc.StreamServerInfo{FullMethod: method}, handler)
+
+	// Assert
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "insufficient permissions: required scope 'stream' not granted", status.Convert(err).Message())
+}
+
 var cases = []struct {
 	name           string
 	jwtToken       string
@@ -130,3 +236,114 @@ var cases = []struct {
 		expectedMsg:    "required claim",
 	},
 }
+
+// TestFindMethodPolicy checks that the policies are matched by full method name, service and prefix, preferring the most specific one
+func TestFindMethodPolicy(t *testing.T) {
+	methods := []MethodPolicy{
+		{MethodName: "/pkg.Service/*", RequiredClaims: []string{"service"}},
+		{MethodName: "/pkg.Service/Get", RequiredClaims: []string{"exact"}},
+		{MethodName: "/pkg.*", RequiredClaims: []string{"package"}},
+		{MethodName: "/grpc.health.v1.Health/*", Public: true},
+	}
+
+	cases := []struct {
+		name          string
+		fullMethod    string
+		expectedFound bool
+		expectedName  string
+	}{
+		{"Exact match", "/pkg.Service/Get", true, "/pkg.Service/Get"},
+		{"Service match", "/pkg.Service/List", true, "/pkg.Service/*"},
+		{"Prefix match", "/pkg.Other/List", true, "/pkg.*"},
+		{"Public service", "/grpc.health.v1.Health/Check", true, "/grpc.health.v1.Health/*"},
+		{"No match", "/other.Service/Get", false, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			policy, found := findMethodPolicy(methods, tt.fullMethod)
+
+			// Assert
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedName, policy.MethodName)
+		})
+	}
+}
+
+// TestUnaryJWT_DefaultDeny checks that the unary interceptor rejects the unlisted methods and allows the public ones when default deny is enabled
+func TestUnaryJWT_DefaultDeny(t *testing.T) {
+	// Arrange
+	interceptor := UnaryJWT("test-secret", []MethodPolicy{{MethodName: "/grpc.health.v1.Health/*", Public: true}}, WithDefaultDeny())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	// Act
+	publicResp, publicErr := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	_, deniedErr := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Get"}, handler)
+
+	// Assert
+	assert.Nil(t, publicErr)
+	assert.Equal(t, "ok", publicResp)
+	assert.Equal(t, codes.PermissionDenied, status.Code(deniedErr))
+	assert.Equal(t, "access to method /pkg.Service/Get is denied", status.Convert(deniedErr).Message())
+}
+
+// TestStreamJWT_DefaultDeny checks that the stream interceptor rejects the unlisted methods when default deny is enabled
+func TestStreamJWT_DefaultDeny(t *testing.T) {
+	// Arrange
+	interceptor := StreamJWT("test-secret", []MethodPolicy{{MethodName: "/pkg.Service/Watch"}}, WithDefaultDeny())
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+
+	// Act
+	err := interceptor(nil, wrappers.NewGRPCServerStream(context.Background()), &grpc.StreamServerInfo{FullMethod: "/pkg.Service/Other"}, handler)
+
+	// Assert
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// TestValidateMethodPolicies checks that the policies not matching any method registered on the server are returned
+func TestValidateMethodPolicies(t *testing.T) {
+	// Arrange
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "pkg.Service",
+		HandlerType: (*interface{})(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "Get"}},
+		Streams:     []grpc.StreamDesc{{StreamName: "Watch", ServerStreams: true}},
+	}, struct{}{})
+
+	methods := []MethodPolicy{
+		{MethodName: "/pkg.Service/Get"},
+		{MethodName: "/pkg.Service/Watch"},
+		{MethodName: "/pkg.Service/*"},
+		{MethodName: "/pkg.Service/Delete"},
+		{MethodName: "/other.Service/*"},
+	}
+
+	// Act
+	unmatched := ValidateMethodPolicies(server, methods)
+
+	// Assert
+	assert.Equal(t, []string{"/pkg.Service/Delete", "/other.Service/*"}, unmatched)
+}
+
+// TestUnaryJWT_ClaimsInAuthContext checks that the interceptor stores the claims where the auth context helpers read them
+func TestUnaryJWT_ClaimsInAuthContext(t *testing.T) {
+	// Arrange
+	method := "/TestService/TestMethod"
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "test-subject"}).SignedString([]byte("test-secret"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+tokenString))
+
+	var subject string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		subject = auth.Subject(ctx)
+		return nil, nil
+	}
+
+	// Act
+	_, err := UnaryJWT("test-secret", []MethodPolicy{{MethodName: method}})(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test-subject", subject)
+}
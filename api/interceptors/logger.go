@@ -2,48 +2,212 @@ package interceptors
 
 import (
 	"context"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
-// UnaryLogger is a gRPC unary interceptor that logs details of the incomming call.
-func UnaryLogger() grpc.UnaryServerInterceptor {
+// RequestIDMetadataKey is the metadata key holding the ID of the request, which is generated when not provided
+const RequestIDMetadataKey = "x-request-id"
+
+// LoggerOption configures the logging interceptors
+type LoggerOption func(*loggerOptions)
+
+type loggerOptions struct {
+	redactor    *observability.Redactor
+	logMessages bool
+}
+
+// WithRedactor masks the sensitive data of the logged messages with the given Redactor instead of observability.DefaultRedactor,
+// nothing is masked when nil
+func WithRedactor(redactor *observability.Redactor) LoggerOption {
+	return func(o *loggerOptions) {
+		o.redactor = redactor
+	}
+}
+
+// WithMessageLogging makes the stream interceptor log each message sent and received, with its size and timing
+func WithMessageLogging() LoggerOption {
+	return func(o *loggerOptions) {
+		o.logMessages = true
+	}
+}
+
+func newLoggerOptions(opts []LoggerOption) loggerOptions {
+	options := loggerOptions{redactor: observability.DefaultRedactor()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// UnaryLogger is a gRPC unary interceptor that logs details of the incomming call as a structured record.
+// It attaches to the call context a logger carrying the request ID, method and trace IDs, retrievable with observability.LoggerFromContext
+func UnaryLogger(opts ...LoggerOption) grpc.UnaryServerInterceptor {
+	options := newLoggerOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		start := time.Now()
 
+		ctx = contextWithLogger(ctx, info.FullMethod)
 		resp, err = handler(ctx, req)
 
-		logResult("Unary", info.FullMethod, start, err, req, resp)
+		attrs := []slog.Attr{slog.Any("request", options.redactor.RedactValue(req))}
+		if err == nil {
+			attrs = append(attrs, slog.Any("response", options.redactor.RedactValue(resp)))
+		}
+		logResult(ctx, options, "unary", start, err, attrs...)
 
 		return resp, err
 	}
 }
 
-// StreamLogger is a gRPC stream interceptor that logs details of the incomming call.
-func StreamLogger() grpc.StreamServerInterceptor {
+// StreamLogger is a gRPC stream interceptor that logs details of the incomming call as a structured record,
+// summarizing the number of messages and bytes sent and received, and optionally logging each message with WithMessageLogging.
+// It attaches to the stream context a logger carrying the request ID, method and trace IDs, retrievable with observability.LoggerFromContext
+func StreamLogger(opts ...LoggerOption) grpc.StreamServerInterceptor {
+	options := newLoggerOptions(opts)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		start := time.Now()
 
-		err := handler(srv, ss)
+		ctx := contextWithLogger(ss.Context(), info.FullMethod)
+		wrappedStream := wrappers.NewGRPCServerStream(ctx)
+		wrappedStream.ServerStream = ss
+		stream := &monitoredServerStream{ServerStream: wrappedStream}
+		if options.logMessages {
+			stream.onMessage = func(direction string, sequence int64, size int, messageStart time.Time, m interface{}) {
+				observability.LoggerFromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "gRPC stream message",
+					slog.String("direction", direction),
+					slog.Int64("sequence", sequence),
+					slog.Int("size", size),
+					slog.Duration("duration", time.Since(messageStart)),
+					slog.Duration("elapsed", time.Since(start)),
+					slog.Any("message", options.redactor.RedactValue(m)),
+				)
+			}
+		}
 
-		logResult("Stream", info.FullMethod, start, err, nil, nil)
+		err := handler(srv, stream)
+
+		logResult(ctx, options, "stream", start, err,
+			slog.Int64("messages_sent", stream.sent.Load()),
+			slog.Int64("messages_received", stream.received.Load()),
+			slog.Int64("bytes_sent", stream.bytesSent.Load()),
+			slog.Int64("bytes_received", stream.bytesReceived.Load()),
+		)
 
 		return err
 	}
 }
 
-func logResult(callType, fullMethod string, start time.Time, err error, req interface{}, resp interface{}) {
+func logResult(ctx context.Context, options loggerOptions, callType string, start time.Time, err error, extra ...slog.Attr) {
 	latency := time.Since(start)
+	code := status.Code(err)
 
+	attrs := []slog.Attr{
+		slog.String("type", callType),
+		slog.String("code", code.String()),
+		slog.Duration("latency", latency),
+	}
+	attrs = append(attrs, extra...)
 	if err != nil {
-		s, _ := status.FromError(err)
-		observability.Logger().Printf("gRPC %s Call: %s - Request: %v - Status: %s - Latency: %s - Error: %v",
-			callType, fullMethod, req, s.Code(), latency, err)
-	} else {
-		observability.Logger().Printf("gRPC %s Call: %s - Request: %v - Status: OK - Latency: %s - Response: %v",
-			callType, fullMethod, req, latency, resp)
+		attrs = append(attrs, slog.String("error", options.redactor.RedactString(err.Error())))
+	}
+
+	observability.LoggerFromContext(ctx).LogAttrs(ctx, grpcLogLevel(code), "gRPC call", attrs...)
+}
+
+// contextWithLogger attaches to the context a logger carrying the request ID, method and trace IDs of the incoming call
+func contextWithLogger(ctx context.Context, fullMethod string) context.Context {
+	requestID, traceparent := "", ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDMetadataKey); len(ids) > 0 {
+			requestID = ids[0]
+		}
+		if values := md.Get("traceparent"); len(values) > 0 {
+			traceparent = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = observability.NewRequestID()
+	}
+
+	logger := observability.RequestLogger(ctx, requestID, traceparent).With(slog.String("method", fullMethod))
+	return observability.ContextWithLogger(ctx, logger)
+}
+
+func grpcLogLevel(code codes.Code) slog.Level {
+	switch {
+	case code == codes.OK:
+		return slog.LevelInfo
+	case isServerError(code):
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// isServerError reports whether the status code denotes a failure of the server rather than of the call
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// monitoredServerStream counts the messages and bytes sent and received through the stream, calling onMessage for each of them when set
+type monitoredServerStream struct {
+	grpc.ServerStream
+	onMessage     func(direction string, sequence int64, size int, start time.Time, m interface{})
+	sent          atomic.Int64
+	received      atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+// SendMsg sends the message through the underlying stream and records it
+func (s *monitoredServerStream) SendMsg(m interface{}) error {
+	start := time.Now()
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		size := messageSize(m)
+		s.bytesSent.Add(int64(size))
+		sequence := s.sent.Add(1)
+		if s.onMessage != nil {
+			s.onMessage("sent", sequence, size, start, m)
+		}
+	}
+	return err
+}
+
+// RecvMsg receives a message from the underlying stream and records it
+func (s *monitoredServerStream) RecvMsg(m interface{}) error {
+	start := time.Now()
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		size := messageSize(m)
+		s.bytesReceived.Add(int64(size))
+		sequence := s.received.Add(1)
+		if s.onMessage != nil {
+			s.onMessage("received", sequence, size, start, m)
+		}
+	}
+	return err
+}
+
+// messageSize returns the encoded size of protobuf messages, and zero for any other message
+func messageSize(m interface{}) int {
+	if message, ok := m.(proto.Message); ok {
+		return proto.Size(message)
 	}
+	return 0
 }
@@ -1,16 +1,25 @@
 package interceptors
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"os"
+	"regexp"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
 	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 // TestUnaryLogger_HandlerOk checks that the unary interceptor preserves the response when the handler returns a successful response
@@ -78,3 +87,311 @@ func TestStreamLogger_HandlerError(t *testing.T) {
 	assert.Equal(t, codes.Internal, status.Code())
 	assert.Equal(t, "test error", status.Message())
 }
+
+// captureLogs configures the structured logger to write JSON records to the returned buffer until the test finishes
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	observability.SetupLogger(buf, observability.LogFormatJSON)
+	t.Cleanup(func() { observability.SetupLogger(os.Stdout, observability.LogFormatText) })
+	return buf
+}
+
+// TestUnaryLogger_StructuredFields checks that the unary interceptor logs the details of the call as key-value fields at the level of the status code
+func TestUnaryLogger_StructuredFields(t *testing.T) {
+	cases := []struct {
+		name          string
+		err           error
+		expectedCode  string
+		expectedLevel string
+	}{
+		{"Success", nil, "OK", "INFO"},
+		{"Client error", status.Error(codes.NotFound, "not found"), "NotFound", "WARN"},
+		{"Server error", status.Error(codes.Internal, "internal"), "Internal", "ERROR"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			buf := captureLogs(t)
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadataKey, "test-request-id"))
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", tt.err
+			}
+
+			// Act
+			UnaryLogger()(ctx, "test-request", &grpc.UnaryServerInfo{FullMethod: "/TestService/TestMethod"}, handler)
+
+			// Assert
+			var record map[string]interface{}
+			assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, tt.expectedLevel, record["level"])
+			assert.Equal(t, "gRPC call", record["msg"])
+			assert.Equal(t, "unary", record["type"])
+			assert.Equal(t, "/TestService/TestMethod", record["method"])
+			assert.Equal(t, tt.expectedCode, record["code"])
+			assert.Equal(t, "test-request-id", record["request_id"])
+			assert.Equal(t, "test-request", record["request"])
+			assert.Contains(t, record, "latency")
+			if tt.err == nil {
+				assert.Equal(t, "ok", record["response"])
+			} else {
+				assert.Contains(t, record, "error")
+			}
+		})
+	}
+}
+
+// TestStreamLogger_StructuredFields checks that the stream interceptor logs the details of the call as key-value fields, generating a request ID when not provided
+func TestStreamLogger_StructuredFields(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		return nil
+	}
+
+	// Act
+	StreamLogger()(nil, wrappers.NewGRPCServerStream(context.Background()), &grpc.StreamServerInfo{FullMethod: "/TestService/TestStreamMethod"}, handler)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "stream", record["type"])
+	assert.Equal(t, "/TestService/TestStreamMethod", record["method"])
+	assert.Equal(t, "OK", record["code"])
+	assert.NotEmpty(t, record["request_id"])
+}
+
+// TestUnaryLogger_ContextLogger checks that the handlers log through the call-scoped logger, which is enriched with the subject of the authenticated caller
+func TestUnaryLogger_ContextLogger(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	method := "/TestService/TestMethod"
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "test-subject"}).SignedString([]byte("test-secret"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", "Bearer "+tokenString,
+		RequestIDMetadataKey, "test-request-id",
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	))
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		observability.LoggerFromContext(ctx).InfoContext(ctx, "handler message")
+		return "ok", nil
+	}
+	jwtHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return UnaryJWT("test-secret", []MethodPolicy{{MethodName: method}})(ctx, req, info, handler)
+	}
+
+	// Act
+	UnaryLogger()(ctx, nil, info, jwtHandler)
+
+	// Assert
+	decoder := json.NewDecoder(buf)
+	for _, expectedMsg := range []string{"handler message", "gRPC call"} {
+		var record map[string]interface{}
+		assert.Nil(t, decoder.Decode(&record))
+		assert.Equal(t, expectedMsg, record["msg"])
+		assert.Equal(t, "test-request-id", record["request_id"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+		assert.Equal(t, method, record["method"])
+		assert.Equal(t, "test-subject", record["subject"])
+	}
+}
+
+// TestStreamLogger_ContextLogger checks that the stream handlers receive a context holding the call-scoped logger
+func TestStreamLogger_ContextLogger(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadataKey, "test-request-id"))
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		observability.LoggerFromContext(ss.Context()).Info("handler message")
+		return nil
+	}
+
+	// Act
+	StreamLogger()(nil, wrappers.NewGRPCServerStream(ctx), &grpc.StreamServerInfo{FullMethod: "/TestService/TestStreamMethod"}, handler)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.NewDecoder(buf).Decode(&record))
+	assert.Equal(t, "handler message", record["msg"])
+	assert.Equal(t, "test-request-id", record["request_id"])
+	assert.Equal(t, "/TestService/TestStreamMethod", record["method"])
+}
+
+// TestUnaryLogger_Redaction checks that the sensitive fields and patterns are masked in the logged request, response and error
+func TestUnaryLogger_Redaction(t *testing.T) {
+	type message struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	redactor := observability.NewRedactor("password")
+	redactor.Patterns = []*regexp.Regexp{observability.EmailPattern}
+
+	cases := []struct {
+		name             string
+		opts             []LoggerOption
+		err              error
+		expectedRequest  interface{}
+		expectedResponse interface{}
+		expectedError    interface{}
+	}{
+		{"Default redactor", nil, nil, map[string]interface{}{"username": "test-user", "password": "[REDACTED]"}, map[string]interface{}{"username": "user@test.com", "password": "[REDACTED]"}, nil},
+		{"Custom redactor", []LoggerOption{WithRedactor(redactor)}, status.Error(codes.NotFound, "user user@test.com not found"), map[string]interface{}{"username": "test-user", "password": "[REDACTED]"}, nil, "rpc error: code = NotFound desc = user [REDACTED] not found"},
+		{"Without redactor", []LoggerOption{WithRedactor(nil)}, nil, map[string]interface{}{"username": "test-user", "password": "test-password"}, map[string]interface{}{"username": "user@test.com", "password": "test-password"}, nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			buf := captureLogs(t)
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return message{"user@test.com", "test-password"}, tt.err
+			}
+
+			// Act
+			UnaryLogger(tt.opts...)(context.Background(), message{"test-user", "test-password"}, &grpc.UnaryServerInfo{FullMethod: "/TestService/TestMethod"}, handler)
+
+			// Assert
+			var record map[string]interface{}
+			assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, tt.expectedRequest, record["request"])
+			assert.Equal(t, tt.expectedResponse, record["response"])
+			assert.Equal(t, tt.expectedError, record["error"])
+		})
+	}
+}
+
+// messageStream is a grpc.ServerStream stand-in receiving the given messages and recording the sent ones
+type messageStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	incoming []string
+	sent     []string
+}
+
+func (s *messageStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *messageStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m.(*wrapperspb.StringValue).GetValue())
+	return nil
+}
+
+func (s *messageStream) RecvMsg(m interface{}) error {
+	if len(s.incoming) == 0 {
+		return io.EOF
+	}
+	m.(*wrapperspb.StringValue).Value = s.incoming[0]
+	s.incoming = s.incoming[1:]
+	return nil
+}
+
+// echoHandler sends back every received message until the end of the stream
+func echoHandler(srv interface{}, ss grpc.ServerStream) error {
+	for {
+		message := &wrapperspb.StringValue{}
+		if err := ss.RecvMsg(message); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := ss.SendMsg(message); err != nil {
+			return err
+		}
+	}
+}
+
+// TestStreamLogger_Summary checks that the stream interceptor summarizes the messages and bytes sent and received without logging each message by default
+func TestStreamLogger_Summary(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	stream := &messageStream{ctx: context.Background(), incoming: []string{"first", "second"}}
+
+	// Act
+	err := StreamLogger()(nil, stream, &grpc.StreamServerInfo{FullMethod: "/TestService/TestStreamMethod"}, echoHandler)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second"}, stream.sent)
+	var record map[string]interface{}
+	decoder := json.NewDecoder(buf)
+	assert.Nil(t, decoder.Decode(&record))
+	assert.False(t, decoder.More())
+	assert.Equal(t, "gRPC call", record["msg"])
+	assert.Equal(t, "OK", record["code"])
+	assert.Equal(t, float64(2), record["messages_sent"])
+	assert.Equal(t, float64(2), record["messages_received"])
+	assert.Equal(t, float64(15), record["bytes_sent"])
+	assert.Equal(t, float64(15), record["bytes_received"])
+	assert.NotContains(t, record, "request")
+	assert.NotContains(t, record, "response")
+}
+
+// TestStreamLogger_MessageLogging checks that the stream interceptor logs each message with its direction, sequence, size and timing when enabled
+func TestStreamLogger_MessageLogging(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	stream := &messageStream{ctx: context.Background(), incoming: []string{"first", "user@test.com"}}
+	redactor := observability.NewRedactor()
+	redactor.Patterns = []*regexp.Regexp{observability.EmailPattern}
+
+	// Act
+	StreamLogger(WithMessageLogging(), WithRedactor(redactor))(nil, stream, &grpc.StreamServerInfo{FullMethod: "/TestService/TestStreamMethod"}, echoHandler)
+
+	// Assert
+	expected := []struct {
+		direction string
+		sequence  float64
+		size      float64
+		value     string
+	}{
+		{"received", 1, 7, "first"},
+		{"sent", 1, 7, "first"},
+		{"received", 2, 15, "[REDACTED]"},
+		{"sent", 2, 15, "[REDACTED]"},
+	}
+	decoder := json.NewDecoder(buf)
+	for _, e := range expected {
+		var record map[string]interface{}
+		assert.Nil(t, decoder.Decode(&record))
+		assert.Equal(t, "gRPC stream message", record["msg"])
+		assert.Equal(t, "/TestService/TestStreamMethod", record["method"])
+		assert.Equal(t, e.direction, record["direction"])
+		assert.Equal(t, e.sequence, record["sequence"])
+		assert.Equal(t, e.size, record["size"])
+		assert.Contains(t, record, "duration")
+		assert.Contains(t, record, "elapsed")
+		assert.Equal(t, e.value, record["message"].(map[string]interface{})["value"])
+	}
+	var summary map[string]interface{}
+	assert.Nil(t, decoder.Decode(&summary))
+	assert.Equal(t, "gRPC call", summary["msg"])
+	assert.Equal(t, float64(22), summary["bytes_sent"])
+}
+
+// TestStreamLogger_RecvError checks that the summary carries the final status code when the stream fails
+func TestStreamLogger_RecvError(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	stream := &messageStream{ctx: context.Background(), incoming: []string{"first"}}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		ss.RecvMsg(&wrapperspb.StringValue{})
+		return status.Error(codes.InvalidArgument, "invalid message")
+	}
+
+	// Act
+	StreamLogger()(nil, stream, &grpc.StreamServerInfo{FullMethod: "/TestService/TestStreamMethod"}, handler)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "InvalidArgument", record["code"])
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, float64(1), record["messages_received"])
+	assert.Equal(t, float64(0), record["messages_sent"])
+}
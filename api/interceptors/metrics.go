@@ -0,0 +1,91 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryMetrics is a gRPC unary interceptor that records the call count, latency, in-flight calls and response size of the incomming calls,
+// labeled by service, method and status code
+func UnaryMetrics(metrics *observability.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		labels := methodLabels("unary", info.FullMethod)
+
+		inFlight := metrics.GRPCInFlight.WithLabelValues(labels...)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		metrics.GRPCMsgsReceived.WithLabelValues(labels...).Inc()
+
+		resp, err := handler(ctx, req)
+
+		var size int
+		if err == nil {
+			size = messageSize(resp)
+			metrics.GRPCMsgsSent.WithLabelValues(labels...).Inc()
+		}
+		observeCall(metrics, labels, start, err, size)
+
+		return resp, err
+	}
+}
+
+// StreamMetrics is a gRPC stream interceptor that records the call count, latency, in-flight calls, messages and response size of the incomming calls,
+// labeled by service, method and status code, the response size being the sum of the sizes of the messages sent
+func StreamMetrics(metrics *observability.Metrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		callType := "bidi_stream"
+		switch {
+		case info.IsClientStream && !info.IsServerStream:
+			callType = "client_stream"
+		case !info.IsClientStream && info.IsServerStream:
+			callType = "server_stream"
+		}
+		labels := methodLabels(callType, info.FullMethod)
+
+		inFlight := metrics.GRPCInFlight.WithLabelValues(labels...)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		received := metrics.GRPCMsgsReceived.WithLabelValues(labels...)
+		sent := metrics.GRPCMsgsSent.WithLabelValues(labels...)
+		stream := &monitoredServerStream{
+			ServerStream: ss,
+			onMessage: func(direction string, _ int64, _ int, _ time.Time, _ interface{}) {
+				if direction == "sent" {
+					sent.Inc()
+				} else {
+					received.Inc()
+				}
+			},
+		}
+
+		err := handler(srv, stream)
+
+		observeCall(metrics, labels, start, err, int(stream.bytesSent.Load()))
+
+		return err
+	}
+}
+
+func observeCall(metrics *observability.Metrics, labels []string, start time.Time, err error, size int) {
+	codeLabels := append(labels[:len(labels):len(labels)], status.Code(err).String())
+	metrics.GRPCRequests.WithLabelValues(codeLabels...).Inc()
+	metrics.GRPCDuration.WithLabelValues(codeLabels...).Observe(time.Since(start).Seconds())
+	metrics.GRPCResponseSize.WithLabelValues(codeLabels...).Observe(float64(size))
+}
+
+// methodLabels returns the type, service and method labels of the full method /package.Service/Method
+func methodLabels(callType, fullMethod string) []string {
+	service, method, found := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !found {
+		return []string{callType, "unknown", fullMethod}
+	}
+	return []string{callType, service, method}
+}
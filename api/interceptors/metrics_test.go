@@ -0,0 +1,72 @@
+package interceptors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestUnaryMetrics checks that the unary interceptor records the count, in-flight calls, messages and response size of the calls labeled by status code
+func TestUnaryMetrics(t *testing.T) {
+	// Arrange
+	metrics := observability.NewMetrics("")
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.TestService/TestMethod"}
+	var inFlight float64
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		inFlight = testutil.ToFloat64(metrics.GRPCInFlight.WithLabelValues("unary", "pkg.TestService", "TestMethod"))
+		if req == nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid request")
+		}
+		return wrapperspb.String("test-response"), nil
+	}
+	interceptor := UnaryMetrics(metrics)
+
+	// Act
+	interceptor(context.Background(), wrapperspb.String("test-request"), info, handler)
+	interceptor(context.Background(), wrapperspb.String("test-request"), info, handler)
+	interceptor(context.Background(), nil, info, handler)
+
+	// Assert
+	assert.Equal(t, float64(1), inFlight)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.GRPCInFlight.WithLabelValues("unary", "pkg.TestService", "TestMethod")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.GRPCRequests.WithLabelValues("unary", "pkg.TestService", "TestMethod", "OK")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.GRPCRequests.WithLabelValues("unary", "pkg.TestService", "TestMethod", "InvalidArgument")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(metrics.GRPCMsgsReceived.WithLabelValues("unary", "pkg.TestService", "TestMethod")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.GRPCMsgsSent.WithLabelValues("unary", "pkg.TestService", "TestMethod")))
+
+	rr := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `grpc_server_handling_seconds_count{grpc_code="OK",grpc_method="TestMethod",grpc_service="pkg.TestService",grpc_type="unary"} 2`)
+	assert.Contains(t, rr.Body.String(), `grpc_server_response_size_bytes_sum{grpc_code="OK",grpc_method="TestMethod",grpc_service="pkg.TestService",grpc_type="unary"} 30`)
+}
+
+// TestStreamMetrics checks that the stream interceptor records the messages and the bytes sent through the stream
+func TestStreamMetrics(t *testing.T) {
+	// Arrange
+	metrics := observability.NewMetrics("")
+	stream := &messageStream{ctx: context.Background(), incoming: []string{"first", "second"}}
+	info := &grpc.StreamServerInfo{FullMethod: "/pkg.TestService/TestStreamMethod", IsClientStream: true, IsServerStream: true}
+
+	// Act
+	err := StreamMetrics(metrics)(nil, stream, info, echoHandler)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first", "second"}, stream.sent)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.GRPCRequests.WithLabelValues("bidi_stream", "pkg.TestService", "TestStreamMethod", "OK")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.GRPCMsgsReceived.WithLabelValues("bidi_stream", "pkg.TestService", "TestStreamMethod")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.GRPCMsgsSent.WithLabelValues("bidi_stream", "pkg.TestService", "TestStreamMethod")))
+
+	rr := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `grpc_server_response_size_bytes_sum{grpc_code="OK",grpc_method="TestStreamMethod",grpc_service="pkg.TestService",grpc_type="bidi_stream"} 15`)
+}
@@ -0,0 +1,44 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// UnaryMTLS is a configurable gRPC unary interceptor that extracts the identity of the verified client certificate and applies the method policy,
+// whose rules can allow-list the peers with auth.AllowPeers, for the incomming call. The server must be configured with TLS credentials verifying client certificates
+func UnaryMTLS(methods []MethodPolicy, opts ...JWTOption) grpc.UnaryServerInterceptor {
+	return unaryAuth(methods, opts, mtlsValidator)
+}
+
+// StreamMTLS is a configurable gRPC stream interceptor that extracts the identity of the verified client certificate and applies the method policy,
+// whose rules can allow-list the peers with auth.AllowPeers, for the incomming call. The server must be configured with TLS credentials verifying client certificates
+func StreamMTLS(methods []MethodPolicy, opts ...JWTOption) grpc.StreamServerInterceptor {
+	return streamAuth(methods, opts, mtlsValidator)
+}
+
+func mtlsValidator(ctx context.Context, policy MethodPolicy) (context.Context, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(errors.New("peer is not provided")))
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, utils.ToGRPC(wrappers.NewUnauthorizedErr(errors.New("verified client certificate is not provided")))
+	}
+
+	identity, err := auth.IdentityFromConnectionState(&tlsInfo.State)
+	if err != nil {
+		return nil, utils.ToGRPC(err)
+	}
+
+	return applyPolicy(ctx, identity.Claims(), policy)
+}
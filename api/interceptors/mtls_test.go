@@ -0,0 +1,91 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/testutils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// TestUnaryMTLS checks that the unary mTLS interceptor correctly handles all expected scenarios over a real mTLS connection
+func TestUnaryMTLS(t *testing.T) {
+	ca := testutils.NewCertificateAuthority(t)
+	methods := []MethodPolicy{{MethodName: "/grpc.health.v1.Health/*", Rules: []auth.ClaimRule{auth.AllowPeers("orders", "spiffe://example.org/ns/prod/*")}}}
+
+	var subject string
+	recordSubject := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		subject = auth.Subject(ctx)
+		return handler(ctx, req)
+	}
+
+	serverCreds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{ca.IssueCertificate(t, "server", "127.0.0.1")},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    ca.Pool,
+	})
+	server := grpc.NewServer(grpc.Creds(serverCreds), grpc.ChainUnaryInterceptor(UnaryMTLS(methods), recordSubject))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(lis)
+	defer server.Stop()
+
+	cases := []struct {
+		name            string
+		certificates    []tls.Certificate
+		expectedCode    codes.Code
+		expectedSubject string
+	}{
+		{"Allowed SPIFFE ID", []tls.Certificate{ca.IssueCertificate(t, "payments", "spiffe://example.org/ns/prod/payments")}, codes.OK, "spiffe://example.org/ns/prod/payments"},
+		{"Allowed common name", []tls.Certificate{ca.IssueCertificate(t, "orders")}, codes.OK, "orders"},
+		{"Not allowed peer", []tls.Certificate{ca.IssueCertificate(t, "payments", "spiffe://example.org/ns/dev/payments")}, codes.PermissionDenied, ""},
+		{"Without client certificate", nil, codes.Unauthenticated, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			subject = ""
+			clientCreds := credentials.NewTLS(&tls.Config{RootCAs: ca.Pool, Certificates: tt.certificates})
+			conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(clientCreds))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			// Act
+			_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedSubject, subject)
+		})
+	}
+}
+
+// TestStreamMTLS_WithoutTLS checks that the stream mTLS interceptor rejects the calls without a TLS peer
+func TestStreamMTLS_WithoutTLS(t *testing.T) {
+	// Arrange
+	interceptor := StreamMTLS([]MethodPolicy{{MethodName: "/pkg.Service/*"}})
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+
+	// Act
+	err := interceptor(nil, wrappers.NewGRPCServerStream(context.Background()), &grpc.StreamServerInfo{FullMethod: "/pkg.Service/Watch"}, handler)
+
+	// Assert
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "peer is not provided", status.Convert(err).Message())
+}
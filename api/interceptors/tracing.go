@@ -0,0 +1,123 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryTracing is a gRPC unary interceptor that starts a server span for the incomming call with the global tracer provider,
+// continuing the trace of the W3C traceparent metadata when provided. The trace and span IDs are added to the logger of the context,
+// so the calls are logged with them whether the logging interceptor is chained before or after it
+func UnaryTracing() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamTracing is a gRPC stream interceptor that starts a server span for the incomming call with the global tracer provider,
+// continuing the trace of the W3C traceparent metadata when provided. The trace and span IDs are added to the logger of the context,
+// so the calls are logged with them whether the logging interceptor is chained before or after it
+func StreamTracing() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		wrappedStream := wrappers.NewGRPCServerStream(ctx)
+		wrappedStream.ServerStream = ss
+
+		err := handler(srv, wrappedStream)
+
+		endSpan(span, err)
+		return err
+	}
+}
+
+// UnaryClientTracing is a gRPC unary client interceptor that propagates the trace of the context to the outgoing metadata as W3C traceparent
+func UnaryClientTracing() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(injectTraceContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientTracing is a gRPC stream client interceptor that propagates the trace of the context to the outgoing metadata as W3C traceparent
+func StreamClientTracing() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectTraceContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	name := strings.TrimPrefix(fullMethod, "/")
+	service, method, _ := strings.Cut(name, "/")
+	ctx, span := observability.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method)),
+	)
+	observability.AddLogAttrs(ctx,
+		slog.String("trace_id", span.SpanContext().TraceID().String()),
+		slog.String("span_id", span.SpanContext().SpanID().String()),
+	)
+	return ctx, span
+}
+
+// endSpan records the status code of the call, marking the span as failed for the codes that denote server errors
+func endSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if isServerError(code) {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+}
+
+func injectTraceContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// metadataCarrier adapts the gRPC metadata to a propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,143 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/testutils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// TestUnaryTracing checks that the unary interceptor starts a server span continuing the trace of the traceparent metadata and recording the status code
+func TestUnaryTracing(t *testing.T) {
+	cases := []struct {
+		name           string
+		err            error
+		expectedStatus otelcodes.Code
+	}{
+		{"Success", nil, otelcodes.Unset},
+		{"Client error", status.Error(codes.NotFound, "not found"), otelcodes.Unset},
+		{"Server error", status.Error(codes.Internal, "internal"), otelcodes.Error},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			exporter := testutils.SetupInMemoryTracing(t, "test-service")
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", testTraceparent))
+			var handlerSpan trace.SpanContext
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				handlerSpan = trace.SpanContextFromContext(ctx)
+				return nil, tt.err
+			}
+
+			// Act
+			UnaryTracing()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.TestService/TestMethod"}, handler)
+
+			// Assert
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, "pkg.TestService/TestMethod", spans[0].Name)
+			assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+			assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+			assert.Equal(t, spans[0].SpanContext, handlerSpan)
+			assert.Contains(t, spans[0].Attributes, semconv.RPCService("pkg.TestService"))
+			assert.Contains(t, spans[0].Attributes, semconv.RPCMethod("TestMethod"))
+			assert.Contains(t, spans[0].Attributes, semconv.RPCGRPCStatusCodeKey.Int(int(status.Code(tt.err))))
+			assert.Equal(t, tt.expectedStatus, spans[0].Status.Code)
+		})
+	}
+}
+
+// TestStreamTracing checks that the stream handlers receive a context holding the server span
+func TestStreamTracing(t *testing.T) {
+	// Arrange
+	exporter := testutils.SetupInMemoryTracing(t, "test-service")
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", testTraceparent))
+	var handlerSpan trace.SpanContext
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		handlerSpan = trace.SpanContextFromContext(ss.Context())
+		return nil
+	}
+
+	// Act
+	err := StreamTracing()(nil, wrappers.NewGRPCServerStream(ctx), &grpc.StreamServerInfo{FullMethod: "/pkg.TestService/TestStreamMethod"}, handler)
+
+	// Assert
+	assert.Nil(t, err)
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "pkg.TestService/TestStreamMethod", spans[0].Name)
+	assert.Equal(t, spans[0].SpanContext, handlerSpan)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handlerSpan.TraceID().String())
+}
+
+// TestUnaryTracing_Logger checks that the calls logged by the logging interceptor chained before it carry the trace and span IDs of the server span once
+func TestUnaryTracing_Logger(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	exporter := testutils.SetupInMemoryTracing(t, "test-service")
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", testTraceparent))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.TestService/TestMethod"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	// Act
+	UnaryLogger()(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return UnaryTracing()(ctx, req, info, handler)
+	})
+
+	// Assert
+	assert.Equal(t, 1, strings.Count(buf.String(), "trace_id"))
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "gRPC call", record["msg"])
+	assert.Equal(t, spans[0].SpanContext.TraceID().String(), record["trace_id"])
+	assert.Equal(t, spans[0].SpanContext.SpanID().String(), record["span_id"])
+}
+
+// TestClientTracing checks that the client interceptors propagate the trace of the context to the outgoing metadata, keeping the existing metadata
+func TestClientTracing(t *testing.T) {
+	// Arrange
+	testutils.SetupInMemoryTracing(t, "test-service")
+	ctx, span := observability.Tracer().Start(context.Background(), "test-span")
+	defer span.End()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-test", "value")
+	expected := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+
+	var unaryMD, streamMD metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		unaryMD, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		streamMD, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	}
+
+	// Act
+	UnaryClientTracing()(ctx, "/pkg.TestService/TestMethod", nil, nil, nil, invoker)
+	StreamClientTracing()(ctx, &grpc.StreamDesc{}, nil, "/pkg.TestService/TestStreamMethod", streamer)
+
+	// Assert
+	assert.Equal(t, []string{expected}, unaryMD.Get("traceparent"))
+	assert.Equal(t, []string{"value"}, unaryMD.Get("x-test"))
+	assert.Equal(t, []string{expected}, streamMD.Get("traceparent"))
+}
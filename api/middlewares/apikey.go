@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+)
+
+// DefaultAPIKeyHeader is the header read by the APIKey middleware when none is configured
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// APIKey is a configurable HTTP middleware that validates the API key of the header against the store and applies the claim rules,
+// such as auth.ScopeIncludes, to its principal for the incomming call
+func APIKey(store auth.APIKeyStore, header string, rules ...auth.ClaimRule) func(http.Handler) http.Handler {
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	validator := auth.NewAPIKeyValidator(store)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := validator.Validate(r.Context(), r.Header.Get(header))
+			if err != nil {
+				utils.ErrorResponse(w, err)
+				return
+			}
+
+			if err := auth.ApplyRules(claims, rules); err != nil {
+				utils.ErrorResponse(w, err)
+				return
+			}
+
+			r = r.WithContext(auth.ContextWithClaims(r.Context(), claims))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
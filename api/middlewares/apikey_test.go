@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAPIKey checks that the API key middleware correctly handles all expected scenarios
+func TestAPIKey(t *testing.T) {
+	store := auth.NewStaticAPIKeyStore(map[string]auth.Principal{
+		"reader-key": {ID: "reader", Scopes: []string{"orders:read"}},
+		"writer-key": {ID: "writer", Scopes: []string{"orders:read", "orders:write"}},
+	})
+
+	cases := []struct {
+		name            string
+		header          string
+		key             string
+		expectedCode    int
+		expectedSubject string
+	}{
+		{"Valid key with scope", "", "writer-key", http.StatusOK, "writer"},
+		{"Valid key in custom header", "X-Partner-Key", "writer-key", http.StatusOK, "writer"},
+		{"Valid key without scope", "", "reader-key", http.StatusForbidden, ""},
+		{"Invalid key", "", "unknown-key", http.StatusUnauthorized, ""},
+		{"Missing key", "", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://testing", nil)
+			header := tt.header
+			if header == "" {
+				header = DefaultAPIKeyHeader
+			}
+			if tt.key != "" {
+				req.Header.Add(header, tt.key)
+			}
+
+			var subject string
+			handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				subject = auth.Subject(r.Context())
+			})
+			handlerToTest := APIKey(store, tt.header, auth.ScopeIncludes("orders:write"))(handlerFunc)
+
+			// Act
+			handlerToTest.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Equal(t, tt.expectedSubject, subject)
+		})
+	}
+}
@@ -2,61 +2,58 @@ package middlewares
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"net/http"
-	"strings"
 
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
 	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
-	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
 )
 
-type claimsCtxKey string
+// ClaimsKey is the context key of the claims of the authenticated caller
+//
+// Deprecated: use auth.ClaimsFromContext to read the claims regardless of the transport
+const ClaimsKey = auth.ClaimsKey
 
-const ClaimsKey claimsCtxKey = "claims"
-
-// JWT is a configurable HTTP middleware that validates the JWT tokens and its claims for the incomming call
+// JWT is a configurable HTTP middleware that validates the JWT tokens signed with the HMAC secret and its claims for the incomming call
 func JWT(jwtSecret string, requiredClaims ...string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return JWTWithKeys(auth.HMACKey([]byte(jwtSecret)), requiredClaims...)
+}
 
-			authorization := r.Header.Get("Authorization")
-			if authorization == "" {
-				utils.ErrorResponse(w, wrappers.NewUnauthorizedErr(errors.New("authorization token is not provided")))
-				return
-			}
+// JWTWithKeys is a configurable HTTP middleware that validates the JWT tokens with the keys of the KeyProvider and its claims for the incomming call
+func JWTWithKeys(keys auth.KeyProvider, requiredClaims ...string) func(http.Handler) http.Handler {
+	return JWTWithValidator(auth.NewValidator(keys), auth.RequireClaims(requiredClaims...))
+}
 
-			bearerToken := strings.Split(authorization, " ")
-			if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-				utils.ErrorResponse(w, wrappers.NewUnauthorizedErr(errors.New("invalid token format, should be Bearer + {token}")))
-				return
-			}
-			tokenString := bearerToken[1]
-
-			claims := jwt.MapClaims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("signin method not valid")
-				}
-				return []byte(jwtSecret), nil
-			})
-
-			if err != nil || !token.Valid {
-				utils.ErrorResponse(w, wrappers.NewUnauthorizedErr(fmt.Errorf("invalid token: %v", err)))
+// JWTWithValidator is a configurable HTTP middleware that validates the tokens with the TokenValidator and applies the claim rules for the incomming call
+func JWTWithValidator(validator auth.TokenValidator, rules ...auth.ClaimRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			newCtx, err := jwtValidator(r, validator, rules)
+			if err != nil {
+				utils.ErrorResponse(w, err)
 				return
 			}
 
-			for _, requiredClaim := range requiredClaims {
-				if _, ok := claims[requiredClaim]; !ok {
-					utils.ErrorResponse(w, wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: required claim '%s' not found", requiredClaim)))
-					return
-				}
-			}
-
-			newCtx := context.WithValue(r.Context(), ClaimsKey, claims)
 			r = r.WithContext(newCtx)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+func jwtValidator(r *http.Request, validator auth.TokenValidator, rules []auth.ClaimRule) (context.Context, error) {
+	tokenString, err := auth.BearerToken(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := validator.Validate(r.Context(), tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.ApplyRules(claims, rules); err != nil {
+		return nil, err
+	}
+
+	ctx := auth.ContextWithToken(r.Context(), tokenString)
+	return auth.ContextWithClaims(ctx, claims), nil
+}
@@ -2,60 +2,288 @@ package middlewares
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"log/slog"
+	"math/rand/v2"
+	"mime"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
 )
 
-type responseWriterWrap struct {
-	http.ResponseWriter
-	statusCode int
-	body       *bytes.Buffer
+// RequestIDHeader is the header holding the ID of the request, which is generated when not provided and returned in the response
+const RequestIDHeader = "X-Request-ID"
+
+// DefaultMaxBodySize is the maximum number of bytes of each body logged by Logger
+const DefaultMaxBodySize = 4096
+
+const formContentType = "application/x-www-form-urlencoded"
+
+// DefaultSkippedContentTypes are the content types whose bodies are not logged by Logger, matched as prefixes
+var DefaultSkippedContentTypes = []string{
+	"image/",
+	"audio/",
+	"video/",
+	"font/",
+	"multipart/",
+	"text/event-stream",
+	"application/octet-stream",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"application/grpc",
 }
 
-func (rw *responseWriterWrap) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
+// SamplingRule sets the fraction of the calls logged for a path prefix and status class
+type SamplingRule struct {
+	// PathPrefix restricts the rule to the paths starting with it, any path matches when empty
+	PathPrefix string
+	// StatusClass restricts the rule to a status class such as 2 for 2xx responses, any status matches when zero
+	StatusClass int
+	// Rate is the fraction of the matching calls that are logged, from 0 to 1
+	Rate float64
 }
 
-func (rw *responseWriterWrap) Write(data []byte) (int, error) {
-	rw.body.Write(data)
-	return rw.ResponseWriter.Write(data)
+// LoggerConfig configures the logging middleware
+type LoggerConfig struct {
+	// SkippedPaths are the path fragments of the calls that are not logged
+	SkippedPaths []string
+	// Redactor masks the sensitive data of the logged bodies and headers, nothing is masked when nil
+	Redactor *observability.Redactor
+	// LogHeaders logs the request headers when true
+	LogHeaders bool
+	// MaxBodySize is the maximum number of bytes logged of each body, which is truncated beyond it.
+	// DefaultMaxBodySize is used when zero and the bodies are not logged when negative
+	MaxBodySize int
+	// SkippedContentTypes are the content types whose bodies are not logged, matched as prefixes.
+	// DefaultSkippedContentTypes is used when nil
+	SkippedContentTypes []string
+	// SamplingRules set the fraction of the calls logged, the first matching rule applies and the calls matching none are always logged
+	SamplingRules []SamplingRule
 }
 
-// Logger is configurable HTTP middleware that logs details of the incomming call
+// Logger is configurable HTTP middleware that logs details of the incomming call as a structured record,
+// masking the usual credential fields and headers with observability.DefaultRedactor
 func Logger(skippedPaths ...string) func(next http.Handler) http.Handler {
+	return LoggerWithConfig(LoggerConfig{
+		SkippedPaths: skippedPaths,
+		Redactor:     observability.DefaultRedactor(),
+	})
+}
+
+// LoggerWithConfig is configurable HTTP middleware that logs details of the incomming call as a structured record,
+// at error level for 5xx responses, warning level for 4xx responses and info level otherwise.
+// The bodies are captured up to the configured size while they are streamed, so uploads and streaming responses are not buffered.
+// It attaches to the request context a logger carrying the request ID, method, path and trace IDs, retrievable with observability.LoggerFromContext
+func LoggerWithConfig(config LoggerConfig) func(next http.Handler) http.Handler {
+	if config.MaxBodySize == 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+	if config.SkippedContentTypes == nil {
+		config.SkippedContentTypes = DefaultSkippedContentTypes
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
-			for _, prefix := range skippedPaths {
+			for _, prefix := range config.SkippedPaths {
 				if strings.Contains(r.URL.Path, prefix) {
 					next.ServeHTTP(w, r)
 					return
 				}
 			}
 
-			body, err := io.ReadAll(r.Body)
-			if err != nil {
-				observability.Logger().Printf("failed to read request body, skiping body loging: %v", err)
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = observability.NewRequestID()
 			}
+			w.Header().Set(RequestIDHeader, requestID)
 
-			r.Body = io.NopCloser(bytes.NewBuffer(body))
+			logger := observability.RequestLogger(r.Context(), requestID, r.Header.Get("traceparent")).With(
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			)
+			ctx := observability.ContextWithLogger(r.Context(), logger)
+			r = r.WithContext(ctx)
+
+			requestBody := &loggedBody{
+				limit:       config.MaxBodySize,
+				contentType: skippedContentType(r.Header.Get("Content-Type"), config.SkippedContentTypes),
+				form:        mediaType(r.Header.Get("Content-Type")) == formContentType,
+			}
+			if requestBody.enabled() && r.Body != nil && r.Body != http.NoBody {
+				if err := requestBody.readPrefix(r); err != nil {
+					logger.WarnContext(ctx, "failed to read request body, skipping body logging", slog.Any("error", err))
+				}
+			}
 
-			rw := &responseWriterWrap{w, http.StatusOK, &bytes.Buffer{}}
+			rw := wrappers.NewResponseWriter(w)
+			responseBody := &loggedBody{limit: config.MaxBodySize, header: rw.Header(), skippedContentTypes: config.SkippedContentTypes}
+			rw.Tee(responseBody)
 
 			next.ServeHTTP(rw, r)
 
+			if !sampled(config.SamplingRules, r.URL.Path, rw.Status()) {
+				return
+			}
+
 			latency := time.Since(start)
 
-			observability.Logger().Printf(
-				"HTTP Call: %s %s - Req Body: %s - Status: %d - Latency: %s - Resp Body: %s",
-				r.Method, r.URL.Path, body, rw.statusCode, latency, rw.body.String(),
-			)
+			attrs := []slog.Attr{
+				slog.Int("status", rw.Status()),
+				slog.Duration("latency", latency),
+				slog.Int64("response_size", rw.BytesWritten()),
+			}
+			if config.MaxBodySize > 0 {
+				attrs = append(attrs,
+					slog.String("request_body", requestBody.format(config.Redactor)),
+					slog.String("response_body", responseBody.format(config.Redactor)),
+				)
+			}
+			if config.LogHeaders {
+				attrs = append(attrs, slog.Any("request_headers", config.Redactor.RedactHeaders(r.Header)))
+			}
+
+			observability.LoggerFromContext(ctx).LogAttrs(ctx, httpLogLevel(rw.Status()), "HTTP call", attrs...)
 		})
 	}
 }
+
+// loggedBody captures the beginning of a body up to its limit, recording its full size
+type loggedBody struct {
+	limit       int
+	data        []byte
+	size        int64
+	truncated   bool
+	contentType string
+	// form is set for URL-encoded form bodies, which are redacted as forms instead of as JSON
+	form bool
+	// header and skippedContentTypes detect the skipped content types of the response bodies on their first write
+	header              http.Header
+	skippedContentTypes []string
+}
+
+func (b *loggedBody) enabled() bool {
+	return b.limit > 0 && b.contentType == ""
+}
+
+// readPrefix reads the beginning of the request body and restores it so that the handler reads it whole
+func (b *loggedBody) readPrefix(r *http.Request) error {
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, int64(b.limit)+1))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), r.Body), r.Body}
+	if err != nil {
+		return err
+	}
+
+	b.capture(prefix)
+	if b.truncated {
+		// The full size is only known from the Content-Length header, otherwise the truncation is logged without it
+		b.size = r.ContentLength
+	}
+	return nil
+}
+
+// Write captures the response body, deciding on the first write whether its content type is skipped
+func (b *loggedBody) Write(data []byte) (int, error) {
+	if b.size == 0 && b.contentType == "" {
+		contentType := b.header.Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		b.contentType = skippedContentType(contentType, b.skippedContentTypes)
+		b.form = mediaType(contentType) == formContentType
+	}
+
+	b.capture(data)
+	return len(data), nil
+}
+
+func (b *loggedBody) capture(data []byte) {
+	b.size += int64(len(data))
+	if !b.enabled() {
+		return
+	}
+
+	room := b.limit - len(b.data)
+	if len(data) > room {
+		data = data[:max(room, 0)]
+		b.truncated = true
+	}
+	b.data = append(b.data, data...)
+}
+
+func (b *loggedBody) format(redactor *observability.Redactor) string {
+	if b.contentType != "" {
+		return fmt.Sprintf("[omitted %s body]", b.contentType)
+	}
+
+	body := redactor.RedactJSON(b.data)
+	if b.form {
+		body = redactor.RedactForm(b.data)
+	}
+	if b.truncated {
+		if truncated := b.size - int64(len(b.data)); truncated > 0 {
+			return fmt.Sprintf("%s...[truncated %d bytes]", body, truncated)
+		}
+		return body + "...[truncated]"
+	}
+	return body
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// skippedContentType returns the media type when it matches any of the skipped content types, or an empty string otherwise
+func skippedContentType(contentType string, skipped []string) string {
+	media := mediaType(contentType)
+	if media == "" {
+		return ""
+	}
+
+	for _, prefix := range skipped {
+		if strings.HasPrefix(media, prefix) {
+			return media
+		}
+	}
+	return ""
+}
+
+// mediaType returns the media type of the Content-Type header without its parameters
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
+// sampled decides whether a call is logged according to the first sampling rule matching its path and status
+func sampled(rules []SamplingRule, path string, statusCode int) bool {
+	for _, rule := range rules {
+		if strings.HasPrefix(path, rule.PathPrefix) && (rule.StatusClass == 0 || rule.StatusClass == statusCode/100) {
+			return rule.Rate >= 1 || (rule.Rate > 0 && rand.Float64() < rule.Rate)
+		}
+	}
+	return true
+}
+
+func httpLogLevel(statusCode int) slog.Level {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return slog.LevelError
+	case statusCode >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
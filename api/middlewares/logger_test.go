@@ -1,13 +1,20 @@
 package middlewares
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -64,3 +71,363 @@ func TestLogger_HandlerError(t *testing.T) {
 	}
 	assert.Equal(t, expectedResponse, response)
 }
+
+// captureLogs configures the structured logger to write JSON records to the returned buffer until the test finishes
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	observability.SetupLogger(buf, observability.LogFormatJSON)
+	t.Cleanup(func() { observability.SetupLogger(os.Stdout, observability.LogFormatText) })
+	return buf
+}
+
+// TestLogger_StructuredFields checks that the middleware logs the details of the call as key-value fields at the level of the status code
+func TestLogger_StructuredFields(t *testing.T) {
+	cases := []struct {
+		name          string
+		statusCode    int
+		requestID     string
+		expectedLevel string
+	}{
+		{"Success with request ID", http.StatusCreated, "test-request-id", "INFO"},
+		{"Client error", http.StatusNotFound, "", "WARN"},
+		{"Server error", http.StatusInternalServerError, "", "ERROR"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			buf := captureLogs(t)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://testing/test-path", strings.NewReader(`{"request":"test-request"}`))
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(`{"response":"test-response"}`))
+			})
+
+			// Act
+			Logger()(handlerFunc).ServeHTTP(rr, req)
+
+			// Assert
+			var record map[string]interface{}
+			assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, tt.expectedLevel, record["level"])
+			assert.Equal(t, "HTTP call", record["msg"])
+			assert.Equal(t, http.MethodPost, record["method"])
+			assert.Equal(t, "/test-path", record["path"])
+			assert.Equal(t, float64(tt.statusCode), record["status"])
+			assert.Contains(t, record, "latency")
+			assert.Equal(t, `{"request":"test-request"}`, record["request_body"])
+			assert.Equal(t, `{"response":"test-response"}`, record["response_body"])
+			assert.NotEmpty(t, record["request_id"])
+			assert.Equal(t, record["request_id"], rr.Header().Get(RequestIDHeader))
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, record["request_id"])
+			}
+		})
+	}
+}
+
+// TestLogger_ContextLogger checks that the handlers log through the request-scoped logger, which is enriched with the subject of the authenticated caller
+func TestLogger_ContextLogger(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "test-subject"}).SignedString([]byte("test-secret"))
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://testing/test-path", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+	req.Header.Set(RequestIDHeader, "test-request-id")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		observability.LoggerFromContext(r.Context()).InfoContext(r.Context(), "handler message")
+	})
+	handlerToTest := Logger()(JWT("test-secret")(handlerFunc))
+
+	// Act
+	handlerToTest.ServeHTTP(rr, req)
+
+	// Assert
+	decoder := json.NewDecoder(buf)
+	for _, expectedMsg := range []string{"handler message", "HTTP call"} {
+		var record map[string]interface{}
+		assert.Nil(t, decoder.Decode(&record))
+		assert.Equal(t, expectedMsg, record["msg"])
+		assert.Equal(t, "test-request-id", record["request_id"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+		assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+		assert.Equal(t, http.MethodGet, record["method"])
+		assert.Equal(t, "/test-path", record["path"])
+		assert.Equal(t, "test-subject", record["subject"])
+	}
+}
+
+// TestLoggerWithConfig_Redaction checks that the sensitive fields, patterns and headers are masked in the logged call
+func TestLoggerWithConfig_Redaction(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	redactor := observability.DefaultRedactor()
+	redactor.Patterns = []*regexp.Regexp{observability.EmailPattern}
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://testing/login", strings.NewReader(`{"email":"user@test.com","password":"test-password"}`))
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Accept", "application/json")
+
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"test-token","expires_in":900}`))
+	})
+	handlerToTest := LoggerWithConfig(LoggerConfig{Redactor: redactor, LogHeaders: true})(handlerFunc)
+
+	// Act
+	handlerToTest.ServeHTTP(rr, req)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, `{"email":"[REDACTED]","password":"[REDACTED]"}`, record["request_body"])
+	assert.Equal(t, `{"access_token":"[REDACTED]","expires_in":900}`, record["response_body"])
+	headers := record["request_headers"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"[REDACTED]"}, headers["Authorization"])
+	assert.Equal(t, []interface{}{"application/json"}, headers["Accept"])
+	assert.Equal(t, `{"access_token":"test-token","expires_in":900}`, rr.Body.String())
+}
+
+// TestLogger_DefaultRedaction checks that the middleware masks the usual credential fields by default
+func TestLogger_DefaultRedaction(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://testing/login", strings.NewReader(`{"username":"test-user","password":"test-password"}`))
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	// Act
+	Logger()(handlerFunc).ServeHTTP(rr, req)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, `{"password":"[REDACTED]","username":"test-user"}`, record["request_body"])
+	assert.NotContains(t, record, "request_headers")
+}
+
+// TestLogger_FormRedaction checks that the middleware masks the credential fields of URL-encoded form bodies
+func TestLogger_FormRedaction(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://testing/login", strings.NewReader("username=test-user&password=test-password"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte("access_token=test-token&token_type=bearer"))
+	})
+
+	// Act
+	Logger()(handlerFunc).ServeHTTP(rr, req)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "username=test-user&password=[REDACTED]", record["request_body"])
+	assert.Equal(t, "access_token=[REDACTED]&token_type=bearer", record["response_body"])
+}
+
+// TestLoggerWithConfig_BodySize checks that the logged bodies are truncated at the maximum size while the handler and the client receive them whole
+func TestLoggerWithConfig_BodySize(t *testing.T) {
+	cases := []struct {
+		name                 string
+		maxBodySize          int
+		unknownLength        bool
+		expectedRequestBody  interface{}
+		expectedResponseBody interface{}
+	}{
+		{"Within limit", 100, false, `{"key":"0123456789abcdefghij"}`, `{"key":"0123456789abcdefghij"}`},
+		{"Truncated", 10, false, `{"key":"01...[truncated 20 bytes]`, `{"key":"01...[truncated 20 bytes]`},
+		{"Truncated with unknown length", 10, true, `{"key":"01...[truncated]`, `{"key":"01...[truncated 20 bytes]`},
+		{"Bodies disabled", -1, false, nil, nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			body := `{"key":"0123456789abcdefghij"}`
+			buf := captureLogs(t)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://testing", strings.NewReader(body))
+			if tt.unknownLength {
+				req.Body = io.NopCloser(strings.NewReader(body))
+				req.ContentLength = -1
+			}
+
+			var receivedBody []byte
+			handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedBody, _ = io.ReadAll(r.Body)
+				w.Write(receivedBody)
+			})
+
+			// Act
+			LoggerWithConfig(LoggerConfig{MaxBodySize: tt.maxBodySize})(handlerFunc).ServeHTTP(rr, req)
+
+			// Assert
+			var record map[string]interface{}
+			assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, tt.expectedRequestBody, record["request_body"])
+			assert.Equal(t, tt.expectedResponseBody, record["response_body"])
+			assert.Equal(t, float64(len(body)), record["response_size"])
+			assert.Equal(t, body, string(receivedBody))
+			assert.Equal(t, body, rr.Body.String())
+		})
+	}
+}
+
+// TestLoggerWithConfig_SkippedContentTypes checks that the bodies of the skipped content types are not logged, detecting the response content type when not set
+func TestLoggerWithConfig_SkippedContentTypes(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	cases := []struct {
+		name                 string
+		requestContentType   string
+		responseContentType  string
+		responseBody         []byte
+		expectedRequestBody  string
+		expectedResponseBody string
+	}{
+		{"Skipped request", "multipart/form-data; boundary=test", "application/json", []byte(`{}`), "[omitted multipart/form-data body]", `{}`},
+		{"Skipped response", "application/json", "application/octet-stream", []byte("binary"), `{"key":"value"}`, "[omitted application/octet-stream body]"},
+		{"Detected response", "application/json", "", png, `{"key":"value"}`, "[omitted image/png body]"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			buf := captureLogs(t)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://testing", strings.NewReader(`{"key":"value"}`))
+			req.Header.Set("Content-Type", tt.requestContentType)
+
+			var receivedBody []byte
+			handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedBody, _ = io.ReadAll(r.Body)
+				if tt.responseContentType != "" {
+					w.Header().Set("Content-Type", tt.responseContentType)
+				}
+				w.Write(tt.responseBody)
+			})
+
+			// Act
+			LoggerWithConfig(LoggerConfig{})(handlerFunc).ServeHTTP(rr, req)
+
+			// Assert
+			var record map[string]interface{}
+			assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, tt.expectedRequestBody, record["request_body"])
+			assert.Equal(t, tt.expectedResponseBody, record["response_body"])
+			assert.Equal(t, `{"key":"value"}`, string(receivedBody))
+			assert.Equal(t, tt.responseBody, rr.Body.Bytes())
+		})
+	}
+}
+
+// TestLoggerWithConfig_Sampling checks that the calls are logged according to the first sampling rule matching their path and status class
+func TestLoggerWithConfig_Sampling(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	config := LoggerConfig{SamplingRules: []SamplingRule{
+		{PathPrefix: "/health", Rate: 0},
+		{StatusClass: 2, Rate: 0},
+		{StatusClass: 4, Rate: 1},
+	}}
+	calls := []struct {
+		path       string
+		statusCode int
+	}{
+		{"/health", http.StatusInternalServerError},
+		{"/users", http.StatusOK},
+		{"/users", http.StatusNotFound},
+		{"/users", http.StatusInternalServerError},
+	}
+
+	// Act
+	for _, call := range calls {
+		handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(call.statusCode)
+		})
+		LoggerWithConfig(config)(handlerFunc).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://testing"+call.path, nil))
+	}
+
+	// Assert
+	var statuses []float64
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]interface{}
+		assert.Nil(t, decoder.Decode(&record))
+		statuses = append(statuses, record["status"].(float64))
+	}
+	assert.Equal(t, []float64{http.StatusNotFound, http.StatusInternalServerError}, statuses)
+}
+
+// TestLogger_Flusher checks that the handlers behind the middleware can flush streaming responses
+func TestLogger_Flusher(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://testing/events", nil)
+
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: test-event\n\n"))
+		w.(http.Flusher).Flush()
+	})
+
+	// Act
+	Logger()(handlerFunc).ServeHTTP(rr, req)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.True(t, rr.Flushed)
+	assert.Equal(t, "data: test-event\n\n", rr.Body.String())
+	assert.Equal(t, "[omitted text/event-stream body]", record["response_body"])
+}
+
+// TestLogger_Hijacker checks that the handlers behind the middleware can take over the connection, logging the protocol switch
+func TestLogger_Hijacker(t *testing.T) {
+	// Arrange
+	buf := captureLogs(t)
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("unexpected error hijacking the connection: %s", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	})
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		Logger()(handlerFunc).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+
+	// Act
+	resp, err := http.DefaultClient.Do(req)
+
+	// Assert
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	<-done
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, float64(http.StatusSwitchingProtocols), record["status"])
+}
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// Metrics is an HTTP middleware that records the request count, latency, in-flight requests and response size of the incomming calls,
+// labeled by method, route pattern and status code. The route is resolved with the RouteResolver, usually the http.ServeMux serving the calls,
+// so the middleware can wrap any other middleware, such as Metrics(metrics, mux)(Logger()(mux)). When it is nil the route is read from the request,
+// so the middleware must wrap the http.ServeMux directly, otherwise the calls are labeled as unmatched
+func Metrics(metrics *observability.Metrics, routes RouteResolver) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			inFlight := metrics.HTTPInFlight.WithLabelValues(r.Method)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			rw := wrappers.NewResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			labels := []string{r.Method, routeLabel(routes, r), strconv.Itoa(rw.Status())}
+			metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+			metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+			metrics.HTTPResponseSize.WithLabelValues(labels...).Observe(float64(rw.BytesWritten()))
+		})
+	}
+}
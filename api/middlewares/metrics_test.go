@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetrics checks that the middleware records the count, latency, in-flight requests and response size of the calls labeled by route pattern
+func TestMetrics(t *testing.T) {
+	// Arrange
+	metrics := observability.NewMetrics("")
+	var inFlight float64
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inFlight = testutil.ToFloat64(metrics.HTTPInFlight.WithLabelValues(http.MethodGet))
+		w.Write([]byte(`{"id":"` + r.PathValue("id") + `"}`))
+	})
+	handlerToTest := Metrics(metrics, mux)(mux)
+
+	// Act
+	for _, path := range []string{"/users/1", "/users/2", "/unknown"} {
+		handlerToTest.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://testing"+path, nil))
+	}
+
+	// Assert
+	assert.Equal(t, float64(1), inFlight)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.HTTPInFlight.WithLabelValues(http.MethodGet)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/users/{id}", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")))
+
+	rr := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `http_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`)
+	assert.Contains(t, rr.Body.String(), `http_response_size_bytes_sum{method="GET",route="/users/{id}",status="200"} 20`)
+}
+
+// TestMetrics_Flusher checks that the handlers behind the middleware keep access to the optional interfaces of the writer
+func TestMetrics_Flusher(t *testing.T) {
+	// Arrange
+	rr := httptest.NewRecorder()
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+	})
+
+	// Act
+	Metrics(observability.NewMetrics(""), nil)(handlerFunc).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://testing", nil))
+
+	// Assert
+	assert.True(t, rr.Flushed)
+}
+
+// TestMetrics_Composed checks that the calls are labeled by the route resolved with the ServeMux when middlewares replacing the request sit between them,
+// and as unmatched without resolver
+func TestMetrics_Composed(t *testing.T) {
+	cases := []struct {
+		name          string
+		resolve       bool
+		expectedRoute string
+	}{
+		{"With resolver", true, "/users/{id}"},
+		{"Without resolver", false, "unmatched"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			metrics := observability.NewMetrics("")
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+			var routes RouteResolver
+			if tt.resolve {
+				routes = mux
+			}
+			withContext := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(w, r.WithContext(r.Context()))
+				})
+			}
+			handlerToTest := Metrics(metrics, routes)(Logger()(withContext(mux)))
+
+			// Act
+			handlerToTest.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://testing/users/1", nil))
+
+			// Assert
+			assert.Equal(t, float64(1), testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, tt.expectedRoute, "200")))
+		})
+	}
+}
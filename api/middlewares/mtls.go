@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+)
+
+// MTLS is a configurable HTTP middleware that extracts the identity of the verified client certificate and applies the claim rules,
+// such as auth.AllowPeers, to it for the incomming call. The server must be configured to request and verify client certificates
+func MTLS(rules ...auth.ClaimRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, err := auth.IdentityFromConnectionState(r.TLS)
+			if err != nil {
+				utils.ErrorResponse(w, err)
+				return
+			}
+
+			claims := identity.Claims()
+			if err := auth.ApplyRules(claims, rules); err != nil {
+				utils.ErrorResponse(w, err)
+				return
+			}
+
+			r = r.WithContext(auth.ContextWithClaims(r.Context(), claims))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMTLS checks that the mTLS middleware correctly handles all expected scenarios over a real TLS connection
+func TestMTLS(t *testing.T) {
+	ca := testutils.NewCertificateAuthority(t)
+
+	var subject string
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject = auth.Subject(r.Context())
+	})
+	server := httptest.NewUnstartedServer(MTLS(auth.AllowPeers("spiffe://example.org/ns/prod/*"))(handlerFunc))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{ca.IssueCertificate(t, "server", "127.0.0.1")},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    ca.Pool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	cases := []struct {
+		name            string
+		certificates    []tls.Certificate
+		expectedCode    int
+		expectedSubject string
+	}{
+		{"Allowed peer", []tls.Certificate{ca.IssueCertificate(t, "orders", "spiffe://example.org/ns/prod/orders")}, http.StatusOK, "spiffe://example.org/ns/prod/orders"},
+		{"Not allowed peer", []tls.Certificate{ca.IssueCertificate(t, "orders", "spiffe://example.org/ns/dev/orders")}, http.StatusForbidden, ""},
+		{"Without client certificate", nil, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			subject = ""
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.Pool, Certificates: tt.certificates}}}
+
+			// Act
+			resp, err := client.Get(server.URL)
+
+			// Assert
+			assert.Nil(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedSubject, subject)
+		})
+	}
+}
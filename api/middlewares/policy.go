@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+)
+
+// RoutePolicy defines the authorization rules of the routes matching its method and path pattern
+type RoutePolicy struct {
+	// Method is the HTTP method of the route, any method when empty
+	Method string
+	// Pattern is the path pattern of the route with the net/http.ServeMux syntax, supporting path params such as /users/{id},
+	// trailing wildcards such as /files/{path...} or /files/* and subtrees such as /public/
+	Pattern string
+	// Public skips the authentication of the route
+	Public bool
+	// RequiredClaims are the claim names that the token must contain
+	RequiredClaims []string
+	// Rules are the checks applied to the claims of the token
+	Rules []auth.ClaimRule
+}
+
+type routePolicyHandler struct {
+	policy RoutePolicy
+}
+
+func (routePolicyHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+// JWTWithPolicies is a configurable HTTP middleware that validates the tokens with the TokenValidator and applies the policy of the matching route for the incomming call.
+// When several policies match a route the most specific one is applied, and routes without policy require a valid token
+// It panics when a pattern is not valid or conflicts with another one
+func JWTWithPolicies(validator auth.TokenValidator, policies []RoutePolicy) func(http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	for _, policy := range policies {
+		mux.Handle(routePattern(policy), routePolicyHandler{policy: policy})
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var policy RoutePolicy
+			if h, _ := mux.Handler(r); h != nil {
+				if ph, ok := h.(routePolicyHandler); ok {
+					policy = ph.policy
+				}
+			}
+
+			if policy.Public {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rules := append([]auth.ClaimRule{auth.RequireClaims(policy.RequiredClaims...)}, policy.Rules...)
+			newCtx, err := jwtValidator(r, validator, rules)
+			if err != nil {
+				utils.ErrorResponse(w, err)
+				return
+			}
+
+			r = r.WithContext(newCtx)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func routePattern(policy RoutePolicy) string {
+	pattern := policy.Pattern
+	if strings.HasSuffix(pattern, "/*") {
+		pattern = strings.TrimSuffix(pattern, "*") + "{wildcard...}"
+	}
+	if policy.Method != "" {
+		pattern = policy.Method + " " + pattern
+	}
+	return pattern
+}
@@ -0,0 +1,89 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestJWTWithPolicies checks that the route policies middleware correctly handles all expected scenarios
+func TestJWTWithPolicies(t *testing.T) {
+	secret := "test-secret"
+	userToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user", "roles": []string{"user"}}).SignedString([]byte(secret))
+	adminToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "admin", "roles": []string{"admin"}, "tenant": "test-tenant"}).SignedString([]byte(secret))
+
+	policies := []RoutePolicy{
+		{Pattern: "/health", Public: true},
+		{Method: http.MethodGet, Pattern: "/docs/", Public: true},
+		{Method: http.MethodGet, Pattern: "/users/{id}"},
+		{Method: http.MethodDelete, Pattern: "/users/{id}", Rules: []auth.ClaimRule{auth.ClaimContainsOneOf("roles", "admin")}},
+		{Pattern: "/admin/*", RequiredClaims: []string{"tenant"}},
+		{Pattern: "/admin/public", Public: true},
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		path         string
+		token        string
+		expectedCode int
+	}{
+		{"Public route without token", http.MethodGet, "/health", "", http.StatusOK},
+		{"Public subtree without token", http.MethodGet, "/docs/index.html", "", http.StatusOK},
+		{"Public subtree with other method", http.MethodPost, "/docs/index.html", "", http.StatusUnauthorized},
+		{"Path param with token", http.MethodGet, "/users/123", userToken, http.StatusOK},
+		{"Path param without token", http.MethodGet, "/users/123", "", http.StatusUnauthorized},
+		{"Rule satisfied", http.MethodDelete, "/users/123", adminToken, http.StatusOK},
+		{"Rule not satisfied", http.MethodDelete, "/users/123", userToken, http.StatusForbidden},
+		{"Wildcard with required claim", http.MethodPost, "/admin/settings/general", adminToken, http.StatusOK},
+		{"Wildcard without required claim", http.MethodPost, "/admin/settings/general", userToken, http.StatusForbidden},
+		{"More specific public route", http.MethodGet, "/admin/public", "", http.StatusOK},
+		{"Route without policy with token", http.MethodGet, "/orders", userToken, http.StatusOK},
+		{"Route without policy without token", http.MethodGet, "/orders", "", http.StatusUnauthorized},
+	}
+
+	var claims interface{}
+	handlerFunc := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = r.Context().Value(ClaimsKey)
+	})
+	handlerToTest := JWTWithPolicies(auth.NewValidator(auth.HMACKey([]byte(secret))), policies)(handlerFunc)
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			claims = nil
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://testing"+tt.path, nil)
+			if tt.token != "" {
+				req.Header.Add("Authorization", "Bearer "+tt.token)
+			}
+
+			// Act
+			handlerToTest.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedCode == http.StatusOK && tt.token != "" {
+				assert.NotNil(t, claims)
+			}
+		})
+	}
+}
+
+// TestJWTWithPolicies_ConflictingPatterns checks that the middleware panics when two policies match the same routes
+func TestJWTWithPolicies_ConflictingPatterns(t *testing.T) {
+	// Arrange
+	policies := []RoutePolicy{
+		{Pattern: "/users/{id}"},
+		{Pattern: "/users/{name}", Public: true},
+	}
+
+	// Act & Assert
+	assert.Panics(t, func() {
+		JWTWithPolicies(auth.NewValidator(auth.HMACKey([]byte("test-secret"))), policies)
+	})
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+)
+
+// unmatchedRoute is the route label of the calls not handled by any http.ServeMux pattern
+const unmatchedRoute = "unmatched"
+
+// RouteResolver returns the pattern of the route handling a request, such as the http.ServeMux serving the calls
+type RouteResolver interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// routeLabel returns the path of the route pattern handling the request without its method, or unmatchedRoute when no pattern matches it.
+// The pattern is resolved with the RouteResolver when provided, or read from the request set by the http.ServeMux that handled it otherwise
+func routeLabel(routes RouteResolver, r *http.Request) string {
+	pattern := r.Pattern
+	if routes != nil {
+		_, pattern = routes.Handler(r)
+	}
+
+	if pattern == "" {
+		return unmatchedRoute
+	}
+	if _, path, found := strings.Cut(pattern, " "); found {
+		return path
+	}
+	return pattern
+}
@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRouteLabel checks that the route is resolved with the RouteResolver when provided and read from the request otherwise, without its method
+func TestRouteLabel(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	cases := []struct {
+		name          string
+		routes        RouteResolver
+		path          string
+		pattern       string
+		expectedLabel string
+	}{
+		{"Resolved pattern with method", mux, "/users/1", "", "/users/{id}"},
+		{"Resolved pattern without method", mux, "/health", "", "/health"},
+		{"Resolved unmatched", mux, "/unknown", "GET /unknown", unmatchedRoute},
+		{"Request pattern", nil, "/users/1", "GET /users/{id}", "/users/{id}"},
+		{"Request without pattern", nil, "/users/1", "", unmatchedRoute},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			r := httptest.NewRequest(http.MethodGet, "http://testing"+tt.path, nil)
+			r.Pattern = tt.pattern
+
+			// Act
+			label := routeLabel(tt.routes, r)
+
+			// Assert
+			assert.Equal(t, tt.expectedLabel, label)
+		})
+	}
+}
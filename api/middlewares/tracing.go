@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing is an HTTP middleware that starts a server span for the incomming call with the global tracer provider, continuing the trace
+// of the W3C traceparent header when provided, and marked as failed for 5xx responses. The span is named after the route pattern resolved
+// with the RouteResolver, usually the http.ServeMux serving the calls, or read from the request when it is nil, in which case the middleware
+// must wrap the http.ServeMux directly. The trace and span IDs are added to the logger of the context, so both Logger()(Tracing(mux)(mux))
+// and Tracing(mux)(Logger()(mux)) log the calls with the IDs of the server span
+func Tracing(routes RouteResolver) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := observability.Tracer().Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+				),
+			)
+			defer span.End()
+			observability.AddLogAttrs(ctx,
+				slog.String("trace_id", span.SpanContext().TraceID().String()),
+				slog.String("span_id", span.SpanContext().SpanID().String()),
+			)
+
+			rw := wrappers.NewResponseWriter(w)
+			r = r.WithContext(ctx)
+
+			next.ServeHTTP(rw, r)
+
+			if route := routeLabel(routes, r); route != unmatchedRoute {
+				span.SetName(fmt.Sprintf("%s %s", r.Method, route))
+				span.SetAttributes(semconv.HTTPRoute(route))
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(rw.Status()))
+			if rw.Status() >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rw.Status()))
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/testutils"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestTracing checks that the middleware starts a server span named after the route pattern, continuing the trace of the traceparent header
+func TestTracing(t *testing.T) {
+	cases := []struct {
+		name           string
+		path           string
+		expectedName   string
+		expectedStatus codes.Code
+	}{
+		{"Matched route", "/users/1", "GET /users/{id}", codes.Unset},
+		{"Server error", "/fail", "GET /fail", codes.Error},
+		{"Unmatched route", "/unknown", "GET", codes.Unset},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			exporter := testutils.SetupInMemoryTracing(t, "test-service")
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+			mux.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+			req := httptest.NewRequest(http.MethodGet, "http://testing"+tt.path, nil)
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+			// Act
+			Tracing(nil)(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, tt.expectedName, spans[0].Name)
+			assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+			assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+			assert.Contains(t, spans[0].Attributes, semconv.URLPath(tt.path))
+			assert.Equal(t, tt.expectedStatus, spans[0].Status.Code)
+		})
+	}
+}
+
+// TestTracing_Logger checks that the calls logged with the logging middleware on either side carry the trace and span IDs of the server span once,
+// and that the span is named after the route pattern
+func TestTracing_Logger(t *testing.T) {
+	cases := []struct {
+		name    string
+		compose func(*http.ServeMux) http.Handler
+	}{
+		{"Logger outside", func(mux *http.ServeMux) http.Handler { return Logger()(Tracing(mux)(mux)) }},
+		{"Tracing outside", func(mux *http.ServeMux) http.Handler { return Tracing(mux)(Logger()(mux)) }},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			buf := captureLogs(t)
+			exporter := testutils.SetupInMemoryTracing(t, "test-service")
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+			req := httptest.NewRequest(http.MethodGet, "http://testing/users/1", nil)
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+			// Act
+			tt.compose(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, 1, strings.Count(buf.String(), "trace_id"))
+			var record map[string]interface{}
+			assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, "GET /users/{id}", spans[0].Name)
+			assert.Equal(t, "HTTP call", record["msg"])
+			assert.Equal(t, spans[0].SpanContext.TraceID().String(), record["trace_id"])
+			assert.Equal(t, spans[0].SpanContext.SpanID().String(), record["span_id"])
+		})
+	}
+}
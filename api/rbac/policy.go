@@ -0,0 +1,156 @@
+package rbac
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/interceptors"
+	"github.com/sergicanet9/scv-go-tools/v4/api/middlewares"
+	"github.com/sergicanet9/scv-go-tools/v4/api/utils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+const (
+	// DefaultRoleClaim is the claim containing the roles when the policy does not define one
+	DefaultRoleClaim = "roles"
+	// AllPermissions is the permission granting any other permission
+	AllPermissions = "*"
+)
+
+// Policy maps the roles to their permissions and the HTTP routes and gRPC methods to the permissions they require
+type Policy struct {
+	// RoleClaim is the path of the claim containing the roles, with dots separating nested claims such as realm_access.roles
+	RoleClaim string `json:"role_claim"`
+	// Roles maps each role to the permissions it grants
+	Roles map[string][]string `json:"roles"`
+	// Routes are the permissions required by the HTTP routes
+	Routes []Route `json:"routes"`
+	// Methods are the permissions required by the gRPC methods
+	Methods []Method `json:"methods"`
+}
+
+// Route defines the permissions required by the HTTP routes matching the method and path pattern
+type Route struct {
+	Method      string   `json:"method"`
+	Pattern     string   `json:"pattern"`
+	Public      bool     `json:"public"`
+	Permissions []string `json:"permissions"`
+}
+
+// Method defines the permissions required by the gRPC methods matching the name
+type Method struct {
+	MethodName  string   `json:"method_name"`
+	Public      bool     `json:"public"`
+	Permissions []string `json:"permissions"`
+}
+
+// LoadPolicy loads the policy from the JSON file
+func LoadPolicy(filePath string) (*Policy, error) {
+	var policy Policy
+	if err := utils.LoadJSON(filePath, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// RolesFromClaims returns the roles found in the role claim, which can be a single role or a list of roles
+func (p *Policy) RolesFromClaims(claims jwt.MapClaims) []string {
+	roleClaim := p.RoleClaim
+	if roleClaim == "" {
+		roleClaim = DefaultRoleClaim
+	}
+
+	var value interface{} = map[string]interface{}(claims)
+	for _, name := range strings.Split(roleClaim, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nested[name]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		roles := make([]string, 0, len(v))
+		for _, role := range v {
+			if r, ok := role.(string); ok {
+				roles = append(roles, r)
+			}
+		}
+		return roles
+	case []string:
+		return v
+	default:
+		return nil
+	}
+}
+
+// Permissions returns the permissions granted by the roles of the claims
+func (p *Policy) Permissions(claims jwt.MapClaims) map[string]bool {
+	permissions := make(map[string]bool)
+	for _, role := range p.RolesFromClaims(claims) {
+		for _, permission := range p.Roles[role] {
+			permissions[permission] = true
+		}
+	}
+	return permissions
+}
+
+// HasPermissions checks that the roles of the claims grant all the permissions
+func (p *Policy) HasPermissions(claims jwt.MapClaims, permissions ...string) bool {
+	return p.missingPermission(claims, permissions) == ""
+}
+
+// Require returns a ClaimRule checking that the roles of the claims grant all the permissions
+func (p *Policy) Require(permissions ...string) auth.ClaimRule {
+	return func(claims jwt.MapClaims) error {
+		if missing := p.missingPermission(claims, permissions); missing != "" {
+			return wrappers.NewUnauthenticatedErr(fmt.Errorf("insufficient permissions: required permission '%s' not granted", missing))
+		}
+		return nil
+	}
+}
+
+// RoutePolicies returns the route policies enforcing the permissions of the routes, to be used with middlewares.JWTWithPolicies
+func (p *Policy) RoutePolicies() []middlewares.RoutePolicy {
+	policies := make([]middlewares.RoutePolicy, 0, len(p.Routes))
+	for _, route := range p.Routes {
+		policies = append(policies, middlewares.RoutePolicy{
+			Method:  route.Method,
+			Pattern: route.Pattern,
+			Public:  route.Public,
+			Rules:   []auth.ClaimRule{p.Require(route.Permissions...)},
+		})
+	}
+	return policies
+}
+
+// MethodPolicies returns the method policies enforcing the permissions of the methods, to be used with the JWT interceptors
+func (p *Policy) MethodPolicies() []interceptors.MethodPolicy {
+	policies := make([]interceptors.MethodPolicy, 0, len(p.Methods))
+	for _, method := range p.Methods {
+		policies = append(policies, interceptors.MethodPolicy{
+			MethodName: method.MethodName,
+			Public:     method.Public,
+			Rules:      []auth.ClaimRule{p.Require(method.Permissions...)},
+		})
+	}
+	return policies
+}
+
+func (p *Policy) missingPermission(claims jwt.MapClaims, permissions []string) string {
+	granted := p.Permissions(claims)
+	if granted[AllPermissions] {
+		return ""
+	}
+	for _, permission := range permissions {
+		if !granted[permission] {
+			return permission
+		}
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sergicanet9/scv-go-tools/v4/api/auth"
+	"github.com/sergicanet9/scv-go-tools/v4/api/interceptors"
+	"github.com/sergicanet9/scv-go-tools/v4/api/middlewares"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `{
+	"role_claim": "realm_access.roles",
+	"roles": {
+		"admin": ["*"],
+		"editor": ["users:read", "users:write"],
+		"viewer": ["users:read"]
+	},
+	"routes": [
+		{"pattern": "/health", "public": true},
+		{"method": "GET", "pattern": "/users/{id}", "permissions": ["users:read"]},
+		{"method": "PUT", "pattern": "/users/{id}", "permissions": ["users:write"]}
+	],
+	"methods": [
+		{"method_name": "/pkg.Users/Get", "permissions": ["users:read"]},
+		{"method_name": "/pkg.Users/*", "permissions": ["users:write"]}
+	]
+}`
+
+func loadTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(filePath, []byte(testPolicy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadPolicy(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func claimsWithRoles(roles ...interface{}) jwt.MapClaims {
+	return jwt.MapClaims{"sub": "test-subject", "realm_access": map[string]interface{}{"roles": roles}}
+}
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenString
+}
+
+// TestLoadPolicy_Ok checks that LoadPolicy loads the roles, routes and methods of the JSON file
+func TestLoadPolicy_Ok(t *testing.T) {
+	// Act
+	policy := loadTestPolicy(t)
+
+	// Assert
+	assert.Equal(t, "realm_access.roles", policy.RoleClaim)
+	assert.Equal(t, []string{"users:read"}, policy.Roles["viewer"])
+	assert.Len(t, policy.Routes, 3)
+	assert.Len(t, policy.Methods, 2)
+}
+
+// TestLoadPolicy_NonExistentFile checks that LoadPolicy returns an error when the file does not exist
+func TestLoadPolicy_NonExistentFile(t *testing.T) {
+	// Act
+	_, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.json"))
+
+	// Assert
+	assert.NotNil(t, err)
+}
+
+// TestRolesFromClaims checks that the roles are read from the configured claim path in all expected formats
+func TestRolesFromClaims(t *testing.T) {
+	cases := []struct {
+		name          string
+		roleClaim     string
+		claims        jwt.MapClaims
+		expectedRoles []string
+	}{
+		{"Default claim list", "", jwt.MapClaims{"roles": []interface{}{"admin", "viewer"}}, []string{"admin", "viewer"}},
+		{"Default claim single role", "", jwt.MapClaims{"roles": "admin"}, []string{"admin"}},
+		{"Nested claim", "realm_access.roles", claimsWithRoles("editor"), []string{"editor"}},
+		{"Missing nested claim", "resource_access.api.roles", claimsWithRoles("editor"), nil},
+		{"Path through non-object", "sub.roles", claimsWithRoles("editor"), nil},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			policy := &Policy{RoleClaim: tt.roleClaim}
+
+			// Act
+			roles := policy.RolesFromClaims(tt.claims)
+
+			// Assert
+			assert.Equal(t, tt.expectedRoles, roles)
+		})
+	}
+}
+
+// TestRequire checks that the rule grants the permissions of the roles and returns an UnauthenticatedErr otherwise
+func TestRequire(t *testing.T) {
+	policy := loadTestPolicy(t)
+
+	cases := []struct {
+		name        string
+		claims      jwt.MapClaims
+		permissions []string
+		expectedErr string
+	}{
+		{"Granted permission", claimsWithRoles("viewer"), []string{"users:read"}, ""},
+		{"Permissions of several roles", claimsWithRoles("viewer", "editor"), []string{"users:read", "users:write"}, ""},
+		{"All permissions", claimsWithRoles("admin"), []string{"users:delete"}, ""},
+		{"Missing permission", claimsWithRoles("viewer"), []string{"users:read", "users:write"}, "insufficient permissions: required permission 'users:write' not granted"},
+		{"Unknown role", claimsWithRoles("guest"), []string{"users:read"}, "insufficient permissions: required permission 'users:read' not granted"},
+		{"No roles", jwt.MapClaims{}, []string{"users:read"}, "insufficient permissions: required permission 'users:read' not granted"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := policy.Require(tt.permissions...)(tt.claims)
+
+			// Assert
+			if tt.expectedErr == "" {
+				assert.Nil(t, err)
+				assert.True(t, policy.HasPermissions(tt.claims, tt.permissions...))
+			} else {
+				assert.True(t, errors.Is(err, wrappers.UnauthenticatedErr))
+				assert.Equal(t, tt.expectedErr, err.Error())
+				assert.False(t, policy.HasPermissions(tt.claims, tt.permissions...))
+			}
+		})
+	}
+}
+
+// TestRoutePolicies checks that the route policies enforce the permissions with the HTTP JWT middleware
+func TestRoutePolicies(t *testing.T) {
+	policy := loadTestPolicy(t)
+	validator := auth.NewValidator(auth.HMACKey([]byte("test-secret")))
+	handlerToTest := middlewares.JWTWithPolicies(validator, policy.RoutePolicies())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct {
+		name         string
+		method       string
+		path         string
+		claims       jwt.MapClaims
+		expectedCode int
+	}{
+		{"Public route", http.MethodGet, "/health", nil, http.StatusOK},
+		{"Granted permission", http.MethodGet, "/users/1", claimsWithRoles("viewer"), http.StatusOK},
+		{"Missing permission", http.MethodPut, "/users/1", claimsWithRoles("viewer"), http.StatusForbidden},
+		{"Missing token", http.MethodPut, "/users/1", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://testing"+tt.path, nil)
+			if tt.claims != nil {
+				req.Header.Add("Authorization", "Bearer "+signToken(t, tt.claims))
+			}
+
+			// Act
+			handlerToTest.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+// TestMethodPolicies checks that the method policies enforce the permissions with the gRPC JWT interceptors
+func TestMethodPolicies(t *testing.T) {
+	policy := loadTestPolicy(t)
+	interceptor := interceptors.UnaryJWT("test-secret", policy.MethodPolicies())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	cases := []struct {
+		name         string
+		method       string
+		claims       jwt.MapClaims
+		expectedCode codes.Code
+	}{
+		{"Granted permission", "/pkg.Users/Get", claimsWithRoles("viewer"), codes.OK},
+		{"Missing permission", "/pkg.Users/Update", claimsWithRoles("viewer"), codes.PermissionDenied},
+		{"Service permission", "/pkg.Users/Update", claimsWithRoles("editor"), codes.OK},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signToken(t, tt.claims)))
+
+			// Act
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+}
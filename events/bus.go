@@ -0,0 +1,139 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+)
+
+// AllEvents is the event type used to subscribe to every event published in a Bus
+const AllEvents = "*"
+
+// Handler processes an event
+type Handler func(ctx context.Context, event Event) error
+
+// Middleware wraps a Handler to add behaviour before or after it
+type Middleware func(next Handler) Handler
+
+// Publisher interface to be used as a port for publishing domain events from the use cases
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Subscriber interface to be used as a port for subscribing to domain events
+type Subscriber interface {
+	Subscribe(eventType string, handler Handler)
+}
+
+// Transport is the adapter point for external brokers, which receive every event published in a Bus
+type Transport interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// Bus is an in-process implementation of the Publisher and Subscriber interfaces
+// A synchronous bus runs the handlers before Publish returns, while an asynchronous one runs them in background
+type Bus struct {
+	async       bool
+	middlewares []Middleware
+
+	mu         sync.RWMutex
+	handlers   map[string][]Handler
+	transports []Transport
+	wg         sync.WaitGroup
+}
+
+// NewSyncBus creates a new bus that runs the handlers synchronously, returning their errors from Publish
+func NewSyncBus(middlewares ...Middleware) *Bus {
+	return newBus(false, middlewares)
+}
+
+// NewAsyncBus creates a new bus that runs every handler in its own goroutine, logging their errors and recovering from their panics
+func NewAsyncBus(middlewares ...Middleware) *Bus {
+	return newBus(true, middlewares)
+}
+
+func newBus(async bool, middlewares []Middleware) *Bus {
+	return &Bus{
+		async:       async,
+		middlewares: middlewares,
+		handlers:    make(map[string][]Handler),
+	}
+}
+
+// Subscribe registers the handler for the given event type, or for every event when AllEvents is used
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	for i := len(b.middlewares) - 1; i >= 0; i-- {
+		handler = b.middlewares[i](handler)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Forward registers a transport that receives every event published in the bus
+func (b *Bus) Forward(transport Transport) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.transports = append(b.transports, transport)
+}
+
+// Publish sends the events to the registered transports and delivers them to the subscribed handlers
+func (b *Bus) Publish(ctx context.Context, events ...Event) error {
+	b.mu.RLock()
+	transports := b.transports
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, event := range events {
+		for _, transport := range transports {
+			if err := transport.Send(ctx, event); err != nil {
+				errs = append(errs, fmt.Errorf("failed to send event %s %s: %w", event.Type, event.ID, err))
+			}
+		}
+		if err := b.Deliver(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Deliver runs the handlers subscribed to the event without sending it to the transports,
+// which is meant for the events received from external brokers
+func (b *Bus) Deliver(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[event.Type]...), b.handlers[AllEvents]...)
+	b.mu.RUnlock()
+
+	ctx = WithCorrelationID(ctx, event.CorrelationID)
+
+	if b.async {
+		ctx = context.WithoutCancel(ctx)
+		for _, handler := range handlers {
+			b.wg.Add(1)
+			go func(handler Handler) {
+				defer b.wg.Done()
+				if err := Recover()(handler)(ctx, event); err != nil {
+					observability.Logger().Printf("failed to handle event %s %s: %v", event.Type, event.ID, err)
+				}
+			}(handler)
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to handle event %s %s: %w", event.Type, event.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wait blocks until all the handlers running in background have finished
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
@@ -0,0 +1,232 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTransport struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func (t *testTransport) Send(ctx context.Context, event Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+	return t.err
+}
+
+// TestSyncBusPublish_Ok checks that a synchronous bus runs the subscribed handlers before Publish returns
+func TestSyncBusPublish_Ok(t *testing.T) {
+	// Arrange
+	bus := NewSyncBus()
+	var received []string
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error {
+		received = append(received, "created:"+event.ID)
+		return nil
+	})
+	bus.Subscribe("test.deleted", func(ctx context.Context, event Event) error {
+		received = append(received, "deleted:"+event.ID)
+		return nil
+	})
+	event := NewEvent(context.Background(), "test.created", nil)
+
+	// Act
+	err := bus.Publish(context.Background(), event)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"created:" + event.ID}, received)
+}
+
+// TestSyncBusPublish_AllEvents checks that the handlers subscribed to AllEvents receive every event
+func TestSyncBusPublish_AllEvents(t *testing.T) {
+	// Arrange
+	bus := NewSyncBus()
+	var received int
+	bus.Subscribe(AllEvents, func(ctx context.Context, event Event) error {
+		received++
+		return nil
+	})
+
+	// Act
+	err := bus.Publish(context.Background(),
+		NewEvent(context.Background(), "test.created", nil),
+		NewEvent(context.Background(), "test.deleted", nil),
+	)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2, received)
+}
+
+// TestSyncBusPublish_HandlerError checks that a synchronous bus returns the errors of the handlers
+func TestSyncBusPublish_HandlerError(t *testing.T) {
+	// Arrange
+	bus := NewSyncBus()
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error {
+		return errors.New("test-error")
+	})
+	event := Event{ID: "1", Type: "test.created"}
+	expectedError := "failed to handle event test.created 1: test-error"
+
+	// Act
+	err := bus.Publish(context.Background(), event)
+
+	// Assert
+	assert.Equal(t, expectedError, err.Error())
+}
+
+// TestBusPublish_CorrelationID checks that the handlers receive a context carrying the correlation ID of the event
+func TestBusPublish_CorrelationID(t *testing.T) {
+	// Arrange
+	bus := NewSyncBus()
+	var correlationID string
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error {
+		correlationID = CorrelationIDFromContext(ctx)
+		return nil
+	})
+	event := NewEvent(WithCorrelationID(context.Background(), "test-correlation-id"), "test.created", nil)
+
+	// Act
+	bus.Publish(context.Background(), event)
+
+	// Assert
+	assert.Equal(t, "test-correlation-id", correlationID)
+}
+
+// TestAsyncBusPublish_Ok checks that an asynchronous bus runs the handlers in background
+func TestAsyncBusPublish_Ok(t *testing.T) {
+	// Arrange
+	bus := NewAsyncBus()
+	release := make(chan struct{})
+	var handled bool
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error {
+		<-release
+		handled = true
+		return errors.New("test-error")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	err := bus.Publish(ctx, NewEvent(ctx, "test.created", nil))
+	cancel()
+	close(release)
+	bus.Wait()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, handled)
+}
+
+// TestAsyncBusPublish_Panic checks that an asynchronous bus recovers from the panics of the handlers and logs them
+func TestAsyncBusPublish_Panic(t *testing.T) {
+	// Arrange
+	buf := &bytes.Buffer{}
+	observability.SetupLogger(buf, observability.LogFormatText)
+	defer observability.SetupLogger(os.Stdout, observability.LogFormatText)
+
+	bus := NewAsyncBus()
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error { panic("test panic") })
+	event := NewEvent(context.Background(), "test.created", nil)
+
+	// Act
+	err := bus.Publish(context.Background(), event)
+	bus.Wait()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "recovered from panic during event test.created "+event.ID+", Panic: test panic")
+}
+
+// TestBusPublish_Transport checks that the published events are sent to the registered transports
+func TestBusPublish_Transport(t *testing.T) {
+	// Arrange
+	bus := NewSyncBus()
+	transport := &testTransport{}
+	bus.Forward(transport)
+	event := NewEvent(context.Background(), "test.created", nil)
+
+	// Act
+	err := bus.Publish(context.Background(), event)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []Event{event}, transport.events)
+}
+
+// TestBusPublish_TransportError checks that Publish returns the errors of the transports and still delivers the event
+func TestBusPublish_TransportError(t *testing.T) {
+	// Arrange
+	bus := NewSyncBus()
+	bus.Forward(&testTransport{err: errors.New("test-error")})
+	var handled bool
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error {
+		handled = true
+		return nil
+	})
+	event := Event{ID: "1", Type: "test.created"}
+	expectedError := "failed to send event test.created 1: test-error"
+
+	// Act
+	err := bus.Publish(context.Background(), event)
+
+	// Assert
+	assert.Equal(t, expectedError, err.Error())
+	assert.True(t, handled)
+}
+
+// TestBusDeliver_SkipsTransports checks that Deliver runs the handlers without sending the event to the transports
+func TestBusDeliver_SkipsTransports(t *testing.T) {
+	// Arrange
+	bus := NewSyncBus()
+	transport := &testTransport{}
+	bus.Forward(transport)
+	var handled bool
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error {
+		handled = true
+		return nil
+	})
+
+	// Act
+	err := bus.Deliver(context.Background(), NewEvent(context.Background(), "test.created", nil))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, handled)
+	assert.Empty(t, transport.events)
+}
+
+// TestBusSubscribe_Middlewares checks that the middlewares wrap the handlers in the given order
+func TestBusSubscribe_Middlewares(t *testing.T) {
+	// Arrange
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, event Event) error {
+				calls = append(calls, name)
+				return next(ctx, event)
+			}
+		}
+	}
+	bus := NewSyncBus(middleware("first"), middleware("second"))
+	bus.Subscribe("test.created", func(ctx context.Context, event Event) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	// Act
+	bus.Publish(context.Background(), NewEvent(context.Background(), "test.created", nil))
+
+	// Assert
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
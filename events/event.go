@@ -0,0 +1,75 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type correlationCtxKey string
+
+const correlationIDKey correlationCtxKey = "correlation-id"
+
+// Event is the envelope of a domain event
+type Event struct {
+	ID            string      `json:"id"`
+	Type          string      `json:"type"`
+	Timestamp     time.Time   `json:"timestamp"`
+	CorrelationID string      `json:"correlation_id"`
+	Payload       interface{} `json:"payload"`
+}
+
+// NewEvent creates a new event of the given type with the payload, taking the correlation ID from the context
+// A new correlation ID is generated when the context does not carry any
+func NewEvent(ctx context.Context, eventType string, payload interface{}) Event {
+	ID := uuid.NewString()
+
+	correlationID := CorrelationIDFromContext(ctx)
+	if correlationID == "" {
+		correlationID = ID
+	}
+
+	return Event{
+		ID:            ID,
+		Type:          eventType,
+		Timestamp:     time.Now().UTC(),
+		CorrelationID: correlationID,
+		Payload:       payload,
+	}
+}
+
+// Decode copies the payload of the event in the received target, either from its original type or from its JSON representation,
+// which is the case of the events received from external brokers
+func (e Event) Decode(target interface{}) error {
+	var data []byte
+	switch payload := e.Payload.(type) {
+	case json.RawMessage:
+		data = payload
+	case []byte:
+		data = payload
+	default:
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal the payload of event %s: %w", e.ID, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to unmarshal the payload of event %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// WithCorrelationID returns a copy of the context carrying the correlation ID, which is set in the events created from it
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by the context, or an empty string when there is none
+func CorrelationIDFromContext(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDKey).(string)
+	return correlationID
+}
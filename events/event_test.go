@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPayload struct {
+	Name string `json:"name"`
+}
+
+// TestNewEvent_Ok checks that NewEvent returns an envelope with the given type and payload
+func TestNewEvent_Ok(t *testing.T) {
+	// Arrange
+	payload := testPayload{Name: "test"}
+
+	// Act
+	event := NewEvent(context.Background(), "test.created", payload)
+
+	// Assert
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), event.ID)
+	assert.Equal(t, "test.created", event.Type)
+	assert.Equal(t, payload, event.Payload)
+	assert.False(t, event.Timestamp.IsZero())
+	assert.Equal(t, event.ID, event.CorrelationID)
+}
+
+// TestNewEvent_CorrelationIDFromContext checks that NewEvent takes the correlation ID from the context
+func TestNewEvent_CorrelationIDFromContext(t *testing.T) {
+	// Arrange
+	ctx := WithCorrelationID(context.Background(), "test-correlation-id")
+
+	// Act
+	event := NewEvent(ctx, "test.created", nil)
+
+	// Assert
+	assert.Equal(t, "test-correlation-id", event.CorrelationID)
+	assert.NotEqual(t, event.ID, event.CorrelationID)
+}
+
+// TestDecode_TypedPayload checks that Decode copies a payload of its original type in the target
+func TestDecode_TypedPayload(t *testing.T) {
+	// Arrange
+	event := NewEvent(context.Background(), "test.created", testPayload{Name: "test"})
+	var target testPayload
+
+	// Act
+	err := event.Decode(&target)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test", target.Name)
+}
+
+// TestDecode_JSONPayload checks that Decode unmarshals the payload of an event received as JSON
+func TestDecode_JSONPayload(t *testing.T) {
+	// Arrange
+	var event Event
+	json.Unmarshal([]byte(`{"id":"1","type":"test.created","payload":{"name":"test"}}`), &event)
+	var target testPayload
+
+	// Act
+	err := event.Decode(&target)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test", target.Name)
+}
+
+// TestDecode_RawPayload checks that Decode unmarshals a raw JSON payload
+func TestDecode_RawPayload(t *testing.T) {
+	// Arrange
+	event := Event{ID: "1", Payload: json.RawMessage(`{"name":"test"}`)}
+	var target testPayload
+
+	// Act
+	err := event.Decode(&target)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test", target.Name)
+}
+
+// TestDecode_InvalidPayload checks that Decode returns an error when the payload cannot be marshalled
+func TestDecode_InvalidPayload(t *testing.T) {
+	// Arrange
+	event := Event{ID: "1", Payload: make(chan int)}
+	expectedError := "failed to marshal the payload of event 1: json: unsupported type: chan int"
+
+	// Act
+	err := event.Decode(&testPayload{})
+
+	// Assert
+	assert.Equal(t, expectedError, err.Error())
+}
+
+// TestDecode_TypeMismatch checks that Decode returns an error when the payload does not match the target
+func TestDecode_TypeMismatch(t *testing.T) {
+	// Arrange
+	event := Event{ID: "1", Payload: []byte(`"test"`)}
+
+	// Act
+	err := event.Decode(&testPayload{})
+
+	// Assert
+	assert.NotEmpty(t, err)
+}
+
+// TestCorrelationIDFromContext_Empty checks that CorrelationIDFromContext returns an empty string when the context carries none
+func TestCorrelationIDFromContext_Empty(t *testing.T) {
+	// Act
+	correlationID := CorrelationIDFromContext(context.Background())
+
+	// Assert
+	assert.Empty(t, correlationID)
+}
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+)
+
+// Logger is a bus middleware that logs details of the handled event
+func Logger() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event Event) error {
+			start := time.Now()
+
+			err := next(ctx, event)
+
+			latency := time.Since(start)
+			if err != nil {
+				observability.Logger().Printf("Event: %s %s - Correlation ID: %s - Status: failed - Latency: %s - Error: %v",
+					event.Type, event.ID, event.CorrelationID, latency, err)
+			} else {
+				observability.Logger().Printf("Event: %s %s - Correlation ID: %s - Status: handled - Latency: %s",
+					event.Type, event.ID, event.CorrelationID, latency)
+			}
+			return err
+		}
+	}
+}
+
+// Recover is a bus middleware that recovers from panics and returns them as an error of the handled event
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("recovered from panic during event %s %s, Panic: %v", event.Type, event.ID, r)
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLogger_Ok checks that the middleware preserves the result of a successful handler
+func TestLogger_Ok(t *testing.T) {
+	// Arrange
+	handler := Logger()(func(ctx context.Context, event Event) error { return nil })
+
+	// Act
+	err := handler(context.Background(), NewEvent(context.Background(), "test.created", nil))
+
+	// Assert
+	assert.Nil(t, err)
+}
+
+// TestLogger_Error checks that the middleware preserves the error of a failing handler
+func TestLogger_Error(t *testing.T) {
+	// Arrange
+	handler := Logger()(func(ctx context.Context, event Event) error { return errors.New("test-error") })
+
+	// Act
+	err := handler(context.Background(), NewEvent(context.Background(), "test.created", nil))
+
+	// Assert
+	assert.Equal(t, "test-error", err.Error())
+}
+
+// TestRecover_NoPanic checks that the middleware does not return an error when no panic happens in the handler
+func TestRecover_NoPanic(t *testing.T) {
+	// Arrange
+	handler := Recover()(func(ctx context.Context, event Event) error { return nil })
+
+	// Act
+	err := handler(context.Background(), Event{ID: "1", Type: "test.created"})
+
+	// Assert
+	assert.Nil(t, err)
+}
+
+// TestRecover_Panic checks that the middleware returns an error when there is a panic in the handler
+func TestRecover_Panic(t *testing.T) {
+	// Arrange
+	handler := Recover()(func(ctx context.Context, event Event) error { panic("test panic") })
+	expectedError := "recovered from panic during event test.created 1, Panic: test panic"
+
+	// Act
+	err := handler(context.Background(), Event{ID: "1", Type: "test.created"})
+
+	// Assert
+	assert.Equal(t, expectedError, err.Error())
+}
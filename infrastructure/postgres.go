@@ -36,6 +36,30 @@ func MigratePostgresDB(db *sql.DB, migrationsDir string) error {
 
 // PostgresRepository struct of a mongo repository
 // Needs a specific implementation of the Repository interface for every entity
+// When a Router is set, the queries are routed through it instead of being executed directly against DB
 type PostgresRepository struct {
-	DB *sql.DB
+	DB     *sql.DB
+	Router *PostgresRouter
+}
+
+// ExecContext executes a query without returning any rows, using the Router when set
+func (r *PostgresRepository) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.querier().ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes a query that returns rows, using the Router when set
+func (r *PostgresRepository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.querier().QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one row, using the Router when set
+func (r *PostgresRepository) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return r.querier().QueryRowContext(ctx, query, args...)
+}
+
+func (r *PostgresRepository) querier() PostgresQuerier {
+	if r.Router != nil {
+		return r.Router
+	}
+	return r.DB
 }
@@ -0,0 +1,283 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+)
+
+const defaultStickyWindow = 5 * time.Second
+
+var (
+	leadingCommentsPattern = regexp.MustCompile(`^(\s+|--[^\n]*|/\*(?s:.*?)\*/)+`)
+	firstKeywordPattern    = regexp.MustCompile(`^[\s(]*([A-Za-z]+)`)
+	lockingClausePattern   = regexp.MustCompile(`(?i)\bFOR\s+(UPDATE|NO\s+KEY\s+UPDATE|SHARE|KEY\s+SHARE)\b`)
+	modifyingPattern       = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE)\b`)
+	intoClausePattern      = regexp.MustCompile(`(?i)\bINTO\b`)
+)
+
+type routerCtxKey string
+
+const (
+	txKey           routerCtxKey = "postgres-tx"
+	writeTrackerKey routerCtxKey = "postgres-write-tracker"
+	forcePrimaryKey routerCtxKey = "postgres-force-primary"
+)
+
+// PostgresQuerier is the set of query operations shared by *sql.DB, *sql.Tx and PostgresRouter
+type PostgresQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// PostgresRouter routes the queries between a primary PostgresDB and its read replicas
+// Writes and transactions always go to the primary, while reads are balanced in round-robin between the healthy replicas
+// Only the statements known to be read-only are considered reads, so that queries such as INSERT ... RETURNING and SELECT ... INTO are sent
+// to the primary, while reads calling functions that modify data must be forced to the primary with WithPrimary
+// Sending the reads to the primary after a recent write is opt-in: it only applies to the contexts returned by WithWriteTracking,
+// which is typically called by a middleware at the start of every request
+type PostgresRouter struct {
+	// StickyWindow is the time during which reads are sent to the primary after a write tracked in the same context
+	StickyWindow time.Duration
+
+	primary  *sql.DB
+	replicas []*postgresReplica
+	next     uint64
+}
+
+type postgresReplica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+type writeTracker struct {
+	mu        sync.Mutex
+	lastWrite time.Time
+}
+
+// NewPostgresRouter creates a new router for the given primary and replicas, considering all the replicas healthy
+func NewPostgresRouter(primary *sql.DB, replicas ...*sql.DB) *PostgresRouter {
+	router := &PostgresRouter{
+		StickyWindow: defaultStickyWindow,
+		primary:      primary,
+	}
+	for _, db := range replicas {
+		replica := &postgresReplica{db: db}
+		replica.healthy.Store(true)
+		router.replicas = append(router.replicas, replica)
+	}
+	return router
+}
+
+// ConnectPostgresRouter opens the connections to the primary and replica PostgresDBs and ensures that all of them are reachable
+func ConnectPostgresRouter(ctx context.Context, primaryDSN string, replicaDSNs ...string) (*PostgresRouter, error) {
+	primary, err := ConnectPostgresDB(ctx, primaryDSN)
+	if err != nil {
+		primary.Close()
+		return nil, fmt.Errorf("primary not reachable: %w", err)
+	}
+
+	var replicas []*sql.DB
+	for i, dsn := range replicaDSNs {
+		replica, err := ConnectPostgresDB(ctx, dsn)
+		if err != nil {
+			replica.Close()
+			primary.Close()
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("replica %d not reachable: %w", i, err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	return NewPostgresRouter(primary, replicas...), nil
+}
+
+// WithWriteTracking returns a copy of the context that records the writes executed through a PostgresRouter,
+// so that the subsequent reads in the same context are sent to the primary during the StickyWindow
+// It is not attached by any middleware of the toolkit, so it must be called once per request before any query is executed,
+// otherwise the reads following a write can be served by a replica that has not applied it yet
+func WithWriteTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeTrackerKey, &writeTracker{})
+}
+
+// WithPrimary returns a copy of the context that forces all the reads to be sent to the primary
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey, true)
+}
+
+// Primary returns the primary db
+func (r *PostgresRouter) Primary() *sql.DB {
+	return r.primary
+}
+
+// Reader returns the db to be used for reads in the given context
+// The primary is returned when it is forced in the context, after a recent write, or when no replica is healthy
+func (r *PostgresRouter) Reader(ctx context.Context) *sql.DB {
+	if forced, _ := ctx.Value(forcePrimaryKey).(bool); forced {
+		return r.primary
+	}
+	if tracker, ok := ctx.Value(writeTrackerKey).(*writeTracker); ok && tracker.wroteWithin(r.StickyWindow) {
+		return r.primary
+	}
+
+	n := len(r.replicas)
+	start := atomic.AddUint64(&r.next, 1)
+	for i := 0; i < n; i++ {
+		replica := r.replicas[(start+uint64(i))%uint64(n)]
+		if replica.healthy.Load() {
+			return replica.db
+		}
+	}
+	return r.primary
+}
+
+// ExecContext executes the query in the transaction of the context if any, or in the primary otherwise
+func (r *PostgresRouter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if tx, ok := ctx.Value(txKey).(*sql.Tx); ok {
+		return tx.ExecContext(ctx, query, args...)
+	}
+
+	result, err := r.primary.ExecContext(ctx, query, args...)
+	if err == nil {
+		trackWrite(ctx)
+	}
+	return result, err
+}
+
+// QueryContext executes the query in the transaction of the context if any,
+// in the db returned by Reader when the query is read-only, or in the primary otherwise
+func (r *PostgresRouter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if tx, ok := ctx.Value(txKey).(*sql.Tx); ok {
+		return tx.QueryContext(ctx, query, args...)
+	}
+	if isReadOnly(query) {
+		return r.Reader(ctx).QueryContext(ctx, query, args...)
+	}
+
+	rows, err := r.primary.QueryContext(ctx, query, args...)
+	if err == nil {
+		trackWrite(ctx)
+	}
+	return rows, err
+}
+
+// QueryRowContext executes the query in the transaction of the context if any,
+// in the db returned by Reader when the query is read-only, or in the primary otherwise
+func (r *PostgresRouter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if tx, ok := ctx.Value(txKey).(*sql.Tx); ok {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	if isReadOnly(query) {
+		return r.Reader(ctx).QueryRowContext(ctx, query, args...)
+	}
+
+	row := r.primary.QueryRowContext(ctx, query, args...)
+	if row.Err() == nil {
+		trackWrite(ctx)
+	}
+	return row
+}
+
+// WithTx begins a transaction in the primary and executes fn with a context carrying it,
+// so that every query executed through the router inside fn uses the transaction
+// The transaction is committed when fn succeeds and rolled back otherwise
+func (r *PostgresRouter) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := r.primary.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w, rollback failed: %v", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	trackWrite(ctx)
+	return nil
+}
+
+// CheckHealth pings every replica and updates its health status accordingly
+func (r *PostgresRouter) CheckHealth(ctx context.Context) {
+	for i, replica := range r.replicas {
+		err := replica.db.PingContext(ctx)
+		wasHealthy := replica.healthy.Swap(err == nil)
+
+		switch {
+		case err != nil && wasHealthy:
+			observability.Logger().Printf("postgres replica %d marked as unhealthy: %v", i, err)
+		case err == nil && !wasHealthy:
+			observability.Logger().Printf("postgres replica %d marked as healthy", i)
+		}
+	}
+}
+
+// StartHealthChecks runs CheckHealth periodically with the given interval until the context is cancelled
+func (r *PostgresRouter) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.CheckHealth(ctx)
+			}
+		}
+	}()
+}
+
+// isReadOnly reports whether the query is known not to modify data nor take row locks, so that it can be sent to a replica
+// Any statement that is not a SELECT without INTO, SHOW, TABLE, VALUES or a WITH without data-modifying statements is conservatively considered a write
+func isReadOnly(query string) bool {
+	query = leadingCommentsPattern.ReplaceAllString(query, "")
+	match := firstKeywordPattern.FindStringSubmatch(query)
+	if match == nil {
+		return false
+	}
+
+	switch strings.ToUpper(match[1]) {
+	case "SELECT":
+		return !lockingClausePattern.MatchString(query) && !intoClausePattern.MatchString(query)
+	case "WITH":
+		return !modifyingPattern.MatchString(query) && !lockingClausePattern.MatchString(query) && !intoClausePattern.MatchString(query)
+	case "SHOW", "TABLE", "VALUES":
+		return true
+	default:
+		return false
+	}
+}
+
+func trackWrite(ctx context.Context) {
+	if tracker, ok := ctx.Value(writeTrackerKey).(*writeTracker); ok {
+		tracker.mu.Lock()
+		tracker.lastWrite = time.Now()
+		tracker.mu.Unlock()
+	}
+}
+
+func (t *writeTracker) wroteWithin(window time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.lastWrite.IsZero() && time.Since(t.lastWrite) < window
+}
@@ -0,0 +1,397 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sergicanet9/scv-go-tools/v4/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+const testReadQuery = "SELECT id FROM test"
+const testWriteQuery = "UPDATE test SET name = 'test'"
+
+func newMonitoredSqlDB(t *testing.T) (sqlmock.Sqlmock, *sql.DB) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mock, db
+}
+
+// TestConnectPostgresRouter_InvalidPrimaryDSN checks that ConnectPostgresRouter returns an error when an invalid primary DSN is provided
+func TestConnectPostgresRouter_InvalidPrimaryDSN(t *testing.T) {
+	// Arrange
+	expectedError := "primary not reachable: missing \"=\" after \"invalid-dsn\" in connection info string\""
+
+	// Act
+	_, err := ConnectPostgresRouter(context.Background(), "invalid-dsn")
+
+	// Assert
+	assert.Equal(t, expectedError, err.Error())
+}
+
+// TestReader_RoundRobin checks that Reader balances the reads between the replicas
+func TestReader_RoundRobin(t *testing.T) {
+	// Arrange
+	_, primary := mocks.NewSqlDB(t)
+	_, replica1 := mocks.NewSqlDB(t)
+	_, replica2 := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica1, replica2)
+
+	// Act
+	first := router.Reader(context.Background())
+	second := router.Reader(context.Background())
+	third := router.Reader(context.Background())
+
+	// Assert
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, first, third)
+	assert.NotEqual(t, primary, first)
+	assert.NotEqual(t, primary, second)
+}
+
+// TestReader_NoReplicas checks that Reader returns the primary when there are no replicas
+func TestReader_NoReplicas(t *testing.T) {
+	// Arrange
+	_, primary := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary)
+
+	// Act
+	reader := router.Reader(context.Background())
+
+	// Assert
+	assert.Equal(t, primary, reader)
+}
+
+// TestReader_ForcedPrimary checks that Reader returns the primary when it is forced in the context
+func TestReader_ForcedPrimary(t *testing.T) {
+	// Arrange
+	_, primary := mocks.NewSqlDB(t)
+	_, replica := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+
+	// Act
+	reader := router.Reader(WithPrimary(context.Background()))
+
+	// Assert
+	assert.Equal(t, primary, reader)
+}
+
+// TestReader_UnhealthyReplica checks that Reader skips the replicas marked as unhealthy
+func TestReader_UnhealthyReplica(t *testing.T) {
+	// Arrange
+	_, primary := mocks.NewSqlDB(t)
+	mock1, replica1 := newMonitoredSqlDB(t)
+	mock2, replica2 := newMonitoredSqlDB(t)
+	router := NewPostgresRouter(primary, replica1, replica2)
+
+	mock1.ExpectPing().WillReturnError(errors.New("test-error"))
+	mock2.ExpectPing()
+	router.CheckHealth(context.Background())
+
+	// Act
+	first := router.Reader(context.Background())
+	second := router.Reader(context.Background())
+
+	// Assert
+	assert.Equal(t, replica2, first)
+	assert.Equal(t, replica2, second)
+	assert.Nil(t, mock1.ExpectationsWereMet())
+	assert.Nil(t, mock2.ExpectationsWereMet())
+}
+
+// TestReader_AllReplicasUnhealthy checks that Reader falls back to the primary when no replica is healthy
+func TestReader_AllReplicasUnhealthy(t *testing.T) {
+	// Arrange
+	_, primary := mocks.NewSqlDB(t)
+	mock, replica := newMonitoredSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+
+	mock.ExpectPing().WillReturnError(errors.New("test-error"))
+	router.CheckHealth(context.Background())
+
+	// Act
+	reader := router.Reader(context.Background())
+
+	// Assert
+	assert.Equal(t, primary, reader)
+}
+
+// TestCheckHealth_ReplicaRecovers checks that CheckHealth marks a replica as healthy again when it becomes reachable
+func TestCheckHealth_ReplicaRecovers(t *testing.T) {
+	// Arrange
+	_, primary := mocks.NewSqlDB(t)
+	mock, replica := newMonitoredSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+
+	mock.ExpectPing().WillReturnError(errors.New("test-error"))
+	mock.ExpectPing()
+	router.CheckHealth(context.Background())
+
+	// Act
+	router.CheckHealth(context.Background())
+
+	// Assert
+	assert.Equal(t, replica, router.Reader(context.Background()))
+}
+
+// TestStartHealthChecks_Ok checks that StartHealthChecks pings the replicas periodically until the context is cancelled
+func TestStartHealthChecks_Ok(t *testing.T) {
+	// Arrange
+	_, primary := mocks.NewSqlDB(t)
+	mock, replica := newMonitoredSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+	mock.ExpectPing().WillReturnError(errors.New("test-error"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	router.StartHealthChecks(ctx, 10*time.Millisecond)
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		return router.Reader(context.Background()) == primary
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestExecContext_WritesToPrimary checks that ExecContext runs the query in the primary
+func TestExecContext_WritesToPrimary(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	replicaMock, replica := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+
+	primaryMock.ExpectExec(testWriteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	_, err := router.ExecContext(context.Background(), testWriteQuery)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
+
+// TestQueryContext_ReadsFromReplica checks that QueryContext runs the query in a replica
+func TestQueryContext_ReadsFromReplica(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	replicaMock, replica := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+
+	replicaMock.ExpectQuery(testReadQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Act
+	rows, err := router.QueryContext(context.Background(), testReadQuery)
+
+	// Assert
+	assert.Nil(t, err)
+	rows.Close()
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
+
+// TestQueryRowContext_ReadAfterWrite checks that the reads are sent to the primary after a write tracked in the same context
+func TestQueryRowContext_ReadAfterWrite(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	replicaMock, replica := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+	ctx := WithWriteTracking(context.Background())
+
+	primaryMock.ExpectExec(testWriteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectQuery(testReadQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Act
+	_, err := router.ExecContext(ctx, testWriteQuery)
+	var id int
+	scanErr := router.QueryRowContext(ctx, testReadQuery).Scan(&id)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, scanErr)
+	assert.Equal(t, 1, id)
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
+
+// TestQueryRowContext_ReadAfterStickyWindow checks that the reads go back to the replicas once the StickyWindow has elapsed
+func TestQueryRowContext_ReadAfterStickyWindow(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	replicaMock, replica := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+	router.StickyWindow = time.Millisecond
+	ctx := WithWriteTracking(context.Background())
+
+	primaryMock.ExpectExec(testWriteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	replicaMock.ExpectQuery(testReadQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Act
+	_, err := router.ExecContext(ctx, testWriteQuery)
+	time.Sleep(5 * time.Millisecond)
+	var id int
+	scanErr := router.QueryRowContext(ctx, testReadQuery).Scan(&id)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, scanErr)
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
+
+// TestWithTx_Commit checks that WithTx runs every query of the function in a primary transaction and commits it
+func TestWithTx_Commit(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	replicaMock, replica := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectQuery(testReadQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	primaryMock.ExpectExec(testWriteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectCommit()
+
+	// Act
+	err := router.WithTx(context.Background(), func(ctx context.Context) error {
+		var id int
+		if err := router.QueryRowContext(ctx, testReadQuery).Scan(&id); err != nil {
+			return err
+		}
+		_, err := router.ExecContext(ctx, testWriteQuery)
+		return err
+	})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
+
+// TestWithTx_Rollback checks that WithTx rolls back the transaction when the function fails
+func TestWithTx_Rollback(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary)
+	expectedError := errors.New("test-error")
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectRollback()
+
+	// Act
+	err := router.WithTx(context.Background(), func(ctx context.Context) error {
+		return expectedError
+	})
+
+	// Assert
+	assert.Equal(t, expectedError, err)
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+}
+
+// TestWithTx_BeginError checks that WithTx returns an error when the transaction cannot be started
+func TestWithTx_BeginError(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary)
+
+	primaryMock.ExpectBegin().WillReturnError(errors.New("test-error"))
+
+	// Act
+	err := router.WithTx(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+
+	// Assert
+	assert.Equal(t, "test-error", err.Error())
+}
+
+// TestWithTx_Nested checks that a nested WithTx reuses the transaction of the context
+func TestWithTx_Nested(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary)
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectExec(testWriteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectCommit()
+
+	// Act
+	err := router.WithTx(context.Background(), func(ctx context.Context) error {
+		return router.WithTx(ctx, func(ctx context.Context) error {
+			_, err := router.ExecContext(ctx, testWriteQuery)
+			return err
+		})
+	})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+}
+
+// TestQueryRowContext_WriteReturning checks that QueryRowContext runs the data-modifying queries returning rows in the primary
+func TestQueryRowContext_WriteReturning(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	replicaMock, replica := mocks.NewSqlDB(t)
+	router := NewPostgresRouter(primary, replica)
+	ctx := WithWriteTracking(context.Background())
+	query := "INSERT INTO test (name) VALUES ($1) RETURNING id"
+
+	primaryMock.ExpectQuery("INSERT INTO test").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	primaryMock.ExpectQuery(testReadQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Act
+	var id int
+	err := router.QueryRowContext(ctx, query, "test").Scan(&id)
+	rows, readErr := router.QueryContext(ctx, testReadQuery)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	assert.Nil(t, readErr)
+	rows.Close()
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
+
+// TestIsReadOnly checks that only the statements known not to modify data are considered read-only
+func TestIsReadOnly(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected bool
+	}{
+		{"SELECT id FROM test", true},
+		{"  select\n\tid FROM test", true},
+		{"-- comment\n/* block\ncomment */ SELECT 1", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"SHOW search_path", true},
+		{"VALUES (1), (2)", true},
+		{"SELECT id FROM test FOR UPDATE", false},
+		{"SELECT id FROM test FOR NO KEY UPDATE SKIP LOCKED", false},
+		{"SELECT * INTO test_copy FROM test", false},
+		{"WITH t AS (SELECT 1) SELECT * INTO test_copy FROM t", false},
+		{"INSERT INTO test (name) VALUES ('test') RETURNING id", false},
+		{"UPDATE test SET name = 'test' RETURNING id", false},
+		{"DELETE FROM test RETURNING id", false},
+		{"WITH t AS (DELETE FROM test RETURNING id) SELECT * FROM t", false},
+		{"CALL test()", false},
+		{"", false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.query, func(t *testing.T) {
+			// Act
+			got := isReadOnly(tt.query)
+
+			// Assert
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
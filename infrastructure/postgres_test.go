@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/sergicanet9/scv-go-tools/v4/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,3 +45,42 @@ func TestMigratePostgresDB_NotValidDirectory(t *testing.T) {
 	// Assert
 	assert.Equal(t, expectedError, err.Error())
 }
+
+// TestPostgresRepositoryQueryContext_WithoutRouter checks that the repository queries DB when no Router is set
+func TestPostgresRepositoryQueryContext_WithoutRouter(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	repo := PostgresRepository{DB: db}
+
+	mock.ExpectQuery(testReadQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Act
+	rows, err := repo.QueryContext(context.Background(), testReadQuery)
+
+	// Assert
+	assert.Nil(t, err)
+	rows.Close()
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresRepository_WithRouter checks that the repository routes writes to the primary and reads to the replicas when a Router is set
+func TestPostgresRepository_WithRouter(t *testing.T) {
+	// Arrange
+	primaryMock, primary := mocks.NewSqlDB(t)
+	replicaMock, replica := mocks.NewSqlDB(t)
+	repo := PostgresRepository{DB: primary, Router: NewPostgresRouter(primary, replica)}
+
+	primaryMock.ExpectExec(testWriteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	replicaMock.ExpectQuery(testReadQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Act
+	_, execErr := repo.ExecContext(context.Background(), testWriteQuery)
+	var id int
+	scanErr := repo.QueryRowContext(context.Background(), testReadQuery).Scan(&id)
+
+	// Assert
+	assert.Nil(t, execErr)
+	assert.Nil(t, scanErr)
+	assert.Nil(t, primaryMock.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
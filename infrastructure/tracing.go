@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/repository"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedRepository is a repository.Repository decorator that wraps every operation in a client span of the global tracer provider
+type TracedRepository struct {
+	repo       repository.Repository
+	system     string
+	collection string
+}
+
+// NewTracedRepository creates a new TracedRepository for the repository, whose spans are labeled with the database system,
+// such as mongodb or postgresql, and the collection or table of the entity
+func NewTracedRepository(repo repository.Repository, system, collection string) *TracedRepository {
+	return &TracedRepository{repo: repo, system: system, collection: collection}
+}
+
+// Create creates the entity within a span
+func (r *TracedRepository) Create(ctx context.Context, entity interface{}) (id string, err error) {
+	ctx, span := r.start(ctx, "create")
+	defer func() { r.end(span, err) }()
+
+	return r.repo.Create(ctx, entity)
+}
+
+// Get gets the entities matching the filter within a span, recording the number of entities returned
+func (r *TracedRepository) Get(ctx context.Context, filter map[string]interface{}, skip, take *int) (entities []interface{}, err error) {
+	ctx, span := r.start(ctx, "get")
+	defer func() { r.end(span, err) }()
+
+	entities, err = r.repo.Get(ctx, filter, skip, take)
+	span.SetAttributes(attribute.Int("db.response.returned_rows", len(entities)))
+	return entities, err
+}
+
+// GetByID gets the entity by its ID within a span
+func (r *TracedRepository) GetByID(ctx context.Context, ID string) (entity interface{}, err error) {
+	ctx, span := r.start(ctx, "get_by_id")
+	defer func() { r.end(span, err) }()
+
+	return r.repo.GetByID(ctx, ID)
+}
+
+// Update updates the entity within a span
+func (r *TracedRepository) Update(ctx context.Context, ID string, entity interface{}) (err error) {
+	ctx, span := r.start(ctx, "update")
+	defer func() { r.end(span, err) }()
+
+	return r.repo.Update(ctx, ID, entity)
+}
+
+// Delete deletes the entity within a span
+func (r *TracedRepository) Delete(ctx context.Context, ID string) (err error) {
+	ctx, span := r.start(ctx, "delete")
+	defer func() { r.end(span, err) }()
+
+	return r.repo.Delete(ctx, ID)
+}
+
+func (r *TracedRepository) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return observability.Tracer().Start(ctx, fmt.Sprintf("%s %s", operation, r.collection),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameKey.String(r.system),
+			semconv.DBCollectionName(r.collection),
+			semconv.DBOperationName(operation),
+		),
+	)
+}
+
+// end records the error in the span, not marking it as failed when the entities do not exist
+func (r *TracedRepository) end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !errors.Is(err, wrappers.NonExistentErr) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
@@ -0,0 +1,107 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	"github.com/sergicanet9/scv-go-tools/v4/testutils"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stubRepository is a repository.Repository stand-in returning the configured error from every operation
+type stubRepository struct {
+	err error
+}
+
+func (r stubRepository) Create(ctx context.Context, entity interface{}) (string, error) {
+	return "test-id", r.err
+}
+
+func (r stubRepository) Get(ctx context.Context, filter map[string]interface{}, skip, take *int) ([]interface{}, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return []interface{}{"first", "second"}, nil
+}
+
+func (r stubRepository) GetByID(ctx context.Context, ID string) (interface{}, error) {
+	return nil, r.err
+}
+
+func (r stubRepository) Update(ctx context.Context, ID string, entity interface{}) error {
+	return r.err
+}
+
+func (r stubRepository) Delete(ctx context.Context, ID string) error {
+	return r.err
+}
+
+// TestTracedRepository checks that every operation is wrapped in a client span of the parent trace
+func TestTracedRepository(t *testing.T) {
+	// Arrange
+	exporter := testutils.SetupInMemoryTracing(t, "test-service")
+	repo := NewTracedRepository(stubRepository{}, "mongodb", "users")
+	ctx, parent := observability.Tracer().Start(context.Background(), "parent")
+
+	// Act
+	id, _ := repo.Create(ctx, nil)
+	entities, _ := repo.Get(ctx, nil, nil, nil)
+	repo.GetByID(ctx, "test-id")
+	repo.Update(ctx, "test-id", nil)
+	repo.Delete(ctx, "test-id")
+	parent.End()
+
+	// Assert
+	assert.Equal(t, "test-id", id)
+	assert.Len(t, entities, 2)
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 6)
+	for i, operation := range []string{"create", "get", "get_by_id", "update", "delete"} {
+		assert.Equal(t, operation+" users", spans[i].Name)
+		assert.Equal(t, trace.SpanKindClient, spans[i].SpanKind)
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[i].Parent.SpanID())
+		assert.Contains(t, spans[i].Attributes, semconv.DBSystemNameKey.String("mongodb"))
+		assert.Contains(t, spans[i].Attributes, semconv.DBCollectionName("users"))
+		assert.Contains(t, spans[i].Attributes, semconv.DBOperationName(operation))
+		assert.Equal(t, codes.Unset, spans[i].Status.Code)
+	}
+	assert.Contains(t, spans[1].Attributes, attribute.Int("db.response.returned_rows", 2))
+}
+
+// TestTracedRepository_Errors checks that the errors are recorded, marking the span as failed unless the entities do not exist
+func TestTracedRepository_Errors(t *testing.T) {
+	cases := []struct {
+		name           string
+		err            error
+		expectedStatus codes.Code
+	}{
+		{"Non existent", wrappers.NewNonExistentErr(errors.New("not found")), codes.Unset},
+		{"Failure", errors.New("connection refused"), codes.Error},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			exporter := testutils.SetupInMemoryTracing(t, "test-service")
+			repo := NewTracedRepository(stubRepository{err: tt.err}, "postgresql", "users")
+
+			// Act
+			_, err := repo.GetByID(context.Background(), "test-id")
+
+			// Assert
+			assert.Equal(t, tt.err, err)
+			spans := exporter.GetSpans()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, tt.expectedStatus, spans[0].Status.Code)
+			assert.Len(t, spans[0].Events, 1)
+			assert.Equal(t, "exception", spans[0].Events[0].Name)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+const defaultMaxAttempts = 3
+
+// Status of a job in the queue
+type Status string
+
+const (
+	// StatusPending is the status of a job waiting to be run
+	StatusPending Status = "pending"
+	// StatusRunning is the status of a job leased by a worker
+	StatusRunning Status = "running"
+	// StatusDone is the status of a job that ran successfully
+	StatusDone Status = "done"
+	// StatusDead is the status of a dead-lettered job that exhausted its attempts
+	StatusDead Status = "dead"
+)
+
+// NoJobsErr is returned by Dequeue when there are no jobs ready to be run
+var NoJobsErr = wrappers.NewNonExistentErr(errors.New("no jobs ready to be run"))
+
+// Job is a unit of asynchronous work
+type Job struct {
+	ID          string
+	Type        string
+	Payload     []byte
+	Status      Status
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LastError   string
+	CreatedAt   time.Time
+}
+
+// Queue interface to be used as a port for enqueuing and leasing jobs
+type Queue interface {
+	// Enqueue adds the job to the queue, to be run at RunAt or as soon as possible when not set
+	Enqueue(ctx context.Context, job Job) (string, error)
+	// Dequeue leases the next job ready to be run for the given duration, returning NoJobsErr when there is none
+	Dequeue(ctx context.Context, lease time.Duration) (*Job, error)
+	// Complete marks the job as done
+	Complete(ctx context.Context, job *Job) error
+	// Retry schedules the job to be run again at the given time
+	Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error
+	// Bury dead-letters the job so that it is not run again
+	Bury(ctx context.Context, job *Job, cause error) error
+}
+
+// NewJob creates a new job of the given type with the JSON-encoded payload
+func NewJob(jobType string, payload interface{}) (Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to marshal the payload: %w", err)
+	}
+	return Job{Type: jobType, Payload: data}, nil
+}
+
+// Decode unmarshals the JSON-encoded payload of the job in the received target
+func (j Job) Decode(target interface{}) error {
+	return json.Unmarshal(j.Payload, target)
+}
+
+func prepare(job Job) Job {
+	now := time.Now()
+	job.Status = StatusPending
+	job.Attempts = 0
+	job.CreatedAt = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = defaultMaxAttempts
+	}
+	return job
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func errNotFound(ID string) error {
+	return fmt.Errorf("job %s not found", ID)
+}
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewJob_Ok checks that NewJob returns a job with the JSON-encoded payload
+func TestNewJob_Ok(t *testing.T) {
+	// Arrange
+	payload := map[string]string{"key": "value"}
+
+	// Act
+	job, err := NewJob("test", payload)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test", job.Type)
+	assert.Equal(t, `{"key":"value"}`, string(job.Payload))
+}
+
+// TestNewJob_InvalidPayload checks that NewJob returns an error when the payload cannot be marshalled
+func TestNewJob_InvalidPayload(t *testing.T) {
+	// Arrange
+	expectedError := "failed to marshal the payload: json: unsupported type: chan int"
+
+	// Act
+	_, err := NewJob("test", make(chan int))
+
+	// Assert
+	assert.Equal(t, expectedError, err.Error())
+}
+
+// TestDecode_Ok checks that Decode unmarshals the payload of the job in the target
+func TestDecode_Ok(t *testing.T) {
+	// Arrange
+	job, _ := NewJob("test", map[string]string{"key": "value"})
+	var target map[string]string
+
+	// Act
+	err := job.Decode(&target)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, target)
+}
+
+// TestPrepare_Defaults checks that prepare sets the default values of a new job
+func TestPrepare_Defaults(t *testing.T) {
+	// Act
+	job := prepare(Job{Type: "test", Attempts: 2})
+
+	// Assert
+	assert.Equal(t, StatusPending, job.Status)
+	assert.Equal(t, 0, job.Attempts)
+	assert.Equal(t, defaultMaxAttempts, job.MaxAttempts)
+	assert.False(t, job.RunAt.IsZero())
+	assert.False(t, job.CreatedAt.IsZero())
+}
+
+// TestPrepare_Delayed checks that prepare preserves the RunAt and MaxAttempts of a delayed job
+func TestPrepare_Delayed(t *testing.T) {
+	// Arrange
+	runAt := time.Now().Add(time.Hour)
+
+	// Act
+	job := prepare(Job{Type: "test", RunAt: runAt, MaxAttempts: 5})
+
+	// Assert
+	assert.Equal(t, runAt, job.RunAt)
+	assert.Equal(t, 5, job.MaxAttempts)
+}
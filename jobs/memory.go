@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// MemoryQueue is an in-memory implementation of the Queue interface, intended for tests
+type MemoryQueue struct {
+	mu   sync.Mutex
+	jobs []*memoryJob
+}
+
+type memoryJob struct {
+	Job
+	lockedUntil time.Time
+}
+
+// NewMemoryQueue creates a new empty in-memory queue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{}
+}
+
+// Enqueue adds the job to the queue
+func (q *MemoryQueue) Enqueue(ctx context.Context, job Job) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job = prepare(job)
+	job.ID = strconv.Itoa(len(q.jobs) + 1)
+	q.jobs = append(q.jobs, &memoryJob{Job: job})
+	return job.ID, nil
+}
+
+// Dequeue leases the job ready to be run with the earliest RunAt, returning NoJobsErr when there is none
+func (q *MemoryQueue) Dequeue(ctx context.Context, lease time.Duration) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var next *memoryJob
+	for _, job := range q.jobs {
+		ready := (job.Status == StatusPending && !job.RunAt.After(now)) ||
+			(job.Status == StatusRunning && job.lockedUntil.Before(now))
+		if ready && (next == nil || job.RunAt.Before(next.RunAt)) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, NoJobsErr
+	}
+
+	next.Status = StatusRunning
+	next.Attempts++
+	next.lockedUntil = now.Add(lease)
+	job := next.Job
+	return &job, nil
+}
+
+// Complete marks the job as done
+func (q *MemoryQueue) Complete(ctx context.Context, job *Job) error {
+	return q.update(job.ID, func(j *memoryJob) {
+		j.Status = StatusDone
+	})
+}
+
+// Retry schedules the job to be run again at the given time
+func (q *MemoryQueue) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	return q.update(job.ID, func(j *memoryJob) {
+		j.Status = StatusPending
+		j.RunAt = runAt
+		j.LastError = errorMessage(cause)
+	})
+}
+
+// Bury dead-letters the job
+func (q *MemoryQueue) Bury(ctx context.Context, job *Job, cause error) error {
+	return q.update(job.ID, func(j *memoryJob) {
+		j.Status = StatusDead
+		j.LastError = errorMessage(cause)
+	})
+}
+
+// Jobs returns a copy of all the jobs in the queue, whatever their status
+func (q *MemoryQueue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, job.Job)
+	}
+	return jobs
+}
+
+func (q *MemoryQueue) update(ID string, fn func(j *memoryJob)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID == ID {
+			fn(job)
+			job.lockedUntil = time.Time{}
+			return nil
+		}
+	}
+	return wrappers.NewNonExistentErr(errNotFound(ID))
+}
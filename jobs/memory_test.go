@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryQueueEnqueue_Ok checks that Enqueue adds a pending job to the queue
+func TestMemoryQueueEnqueue_Ok(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+
+	// Act
+	ID, err := queue.Enqueue(context.Background(), Job{Type: "test"})
+
+	// Assert
+	assert.Nil(t, err)
+	jobs := queue.Jobs()
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, ID, jobs[0].ID)
+	assert.Equal(t, StatusPending, jobs[0].Status)
+}
+
+// TestMemoryQueueDequeue_Ok checks that Dequeue leases the earliest job ready to be run
+func TestMemoryQueueDequeue_Ok(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "later", RunAt: time.Now().Add(-time.Second)})
+	queue.Enqueue(context.Background(), Job{Type: "first", RunAt: time.Now().Add(-time.Minute)})
+
+	// Act
+	job, err := queue.Dequeue(context.Background(), time.Minute)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "first", job.Type)
+	assert.Equal(t, StatusRunning, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+}
+
+// TestMemoryQueueDequeue_Delayed checks that Dequeue does not lease a job scheduled in the future
+func TestMemoryQueueDequeue_Delayed(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "test", RunAt: time.Now().Add(time.Hour)})
+
+	// Act
+	_, err := queue.Dequeue(context.Background(), time.Minute)
+
+	// Assert
+	assert.Equal(t, NoJobsErr, err)
+	assert.True(t, errors.Is(err, wrappers.NonExistentErr))
+}
+
+// TestMemoryQueueDequeue_ExpiredLease checks that Dequeue leases again a running job whose lease has expired
+func TestMemoryQueueDequeue_ExpiredLease(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "test"})
+	queue.Dequeue(context.Background(), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// Act
+	job, err := queue.Dequeue(context.Background(), time.Minute)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2, job.Attempts)
+}
+
+// TestMemoryQueueComplete_Ok checks that Complete marks the job as done
+func TestMemoryQueueComplete_Ok(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "test"})
+	job, _ := queue.Dequeue(context.Background(), time.Minute)
+
+	// Act
+	err := queue.Complete(context.Background(), job)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, StatusDone, queue.Jobs()[0].Status)
+}
+
+// TestMemoryQueueRetry_Ok checks that Retry schedules the job again with the error of the last attempt
+func TestMemoryQueueRetry_Ok(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "test"})
+	job, _ := queue.Dequeue(context.Background(), time.Minute)
+	runAt := time.Now().Add(time.Hour)
+
+	// Act
+	err := queue.Retry(context.Background(), job, runAt, errors.New("test-error"))
+
+	// Assert
+	assert.Nil(t, err)
+	stored := queue.Jobs()[0]
+	assert.Equal(t, StatusPending, stored.Status)
+	assert.Equal(t, runAt, stored.RunAt)
+	assert.Equal(t, "test-error", stored.LastError)
+}
+
+// TestMemoryQueueBury_NotFound checks that Bury returns an error when the job does not exist
+func TestMemoryQueueBury_NotFound(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+
+	// Act
+	err := queue.Bury(context.Background(), &Job{ID: "1"}, errors.New("test-error"))
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.NonExistentErr))
+}
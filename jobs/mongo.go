@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoQueue is an implementation of the Queue interface backed by a MongoDB collection
+// Jobs are leased atomically with findOneAndUpdate, so that concurrent workers never get the same job
+type MongoQueue struct {
+	collection *mongo.Collection
+}
+
+type mongoJob struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Type        string             `bson:"type"`
+	Payload     []byte             `bson:"payload"`
+	Status      Status             `bson:"status"`
+	Attempts    int                `bson:"attempts"`
+	MaxAttempts int                `bson:"max_attempts"`
+	RunAt       time.Time          `bson:"run_at"`
+	LockedUntil *time.Time         `bson:"locked_until,omitempty"`
+	LastError   string             `bson:"last_error"`
+	CreatedAt   time.Time          `bson:"created_at"`
+}
+
+// NewMongoQueue creates a new queue storing the jobs in the given collection
+func NewMongoQueue(collection *mongo.Collection) *MongoQueue {
+	return &MongoQueue{collection: collection}
+}
+
+// EnsureIndexes creates the index used to find the next job ready to be run
+func (q *MongoQueue) EnsureIndexes(ctx context.Context) error {
+	_, err := q.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "run_at", Value: 1}},
+	})
+	return err
+}
+
+// Enqueue adds the job to the collection
+func (q *MongoQueue) Enqueue(ctx context.Context, job Job) (string, error) {
+	job = prepare(job)
+
+	result, err := q.collection.InsertOne(ctx, mongoJob{
+		Type:        job.Type,
+		Payload:     job.Payload,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		CreatedAt:   job.CreatedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// Dequeue leases the job ready to be run with the earliest RunAt, returning NoJobsErr when there is none
+// Running jobs whose lease has expired are considered abandoned and are leased again
+func (q *MongoQueue) Dequeue(ctx context.Context, lease time.Duration) (*Job, error) {
+	now := time.Now()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": StatusPending, "run_at": bson.M{"$lte": now}},
+		bson.M{"status": StatusRunning, "locked_until": bson.M{"$lt": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": StatusRunning, "locked_until": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var result mongoJob
+	if err := q.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, NoJobsErr
+		}
+		return nil, err
+	}
+
+	return &Job{
+		ID:          result.ID.Hex(),
+		Type:        result.Type,
+		Payload:     result.Payload,
+		Status:      result.Status,
+		Attempts:    result.Attempts,
+		MaxAttempts: result.MaxAttempts,
+		RunAt:       result.RunAt,
+		LastError:   result.LastError,
+		CreatedAt:   result.CreatedAt,
+	}, nil
+}
+
+// Complete marks the job as done
+func (q *MongoQueue) Complete(ctx context.Context, job *Job) error {
+	return q.update(ctx, job.ID, bson.M{"status": StatusDone})
+}
+
+// Retry schedules the job to be run again at the given time
+func (q *MongoQueue) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	return q.update(ctx, job.ID, bson.M{"status": StatusPending, "run_at": runAt, "last_error": errorMessage(cause)})
+}
+
+// Bury dead-letters the job
+func (q *MongoQueue) Bury(ctx context.Context, job *Job, cause error) error {
+	return q.update(ctx, job.ID, bson.M{"status": StatusDead, "last_error": errorMessage(cause)})
+}
+
+func (q *MongoQueue) update(ctx context.Context, ID string, set bson.M) error {
+	_id, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": _id}
+	update := bson.M{"$set": set, "$unset": bson.M{"locked_until": ""}}
+	result, err := q.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount < 1 {
+		return wrappers.NewNonExistentErr(errNotFound(ID))
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/mocks"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const testCollectionName = "jobs"
+
+// TestMongoQueueEnsureIndexes_Ok checks that EnsureIndexes does not return an error when the index is created
+func TestMongoQueueEnsureIndexes_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		// Act
+		err := queue.EnsureIndexes(context.Background())
+
+		// Assert
+		assert.Nil(mt, err)
+	})
+}
+
+// TestMongoQueueEnqueue_Ok checks that Enqueue inserts the job and returns its ID
+func TestMongoQueueEnqueue_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		// Act
+		ID, err := queue.Enqueue(context.Background(), Job{Type: "test"})
+
+		// Assert
+		assert.Nil(mt, err)
+		assert.NotEmpty(mt, ID)
+	})
+}
+
+// TestMongoQueueEnqueue_InsertOneError checks that Enqueue returns an error when InsertOne fails
+func TestMongoQueueEnqueue_InsertOneError(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		// Act
+		_, err := queue.Enqueue(context.Background(), Job{Type: "test"})
+
+		// Assert
+		assert.NotEmpty(mt, err)
+	})
+}
+
+// TestMongoQueueDequeue_Ok checks that Dequeue returns the leased job
+func TestMongoQueueDequeue_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		ID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{
+			{Key: "_id", Value: ID},
+			{Key: "type", Value: "test"},
+			{Key: "status", Value: "running"},
+			{Key: "attempts", Value: 1},
+			{Key: "max_attempts", Value: 3},
+		}}))
+
+		// Act
+		job, err := queue.Dequeue(context.Background(), time.Minute)
+
+		// Assert
+		assert.Nil(mt, err)
+		assert.Equal(mt, ID.Hex(), job.ID)
+		assert.Equal(mt, "test", job.Type)
+		assert.Equal(mt, StatusRunning, job.Status)
+		assert.Equal(mt, 1, job.Attempts)
+	})
+}
+
+// TestMongoQueueDequeue_NoJobs checks that Dequeue returns NoJobsErr when there are no jobs ready
+func TestMongoQueueDequeue_NoJobs(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+
+		// Act
+		_, err := queue.Dequeue(context.Background(), time.Minute)
+
+		// Assert
+		assert.Equal(mt, NoJobsErr, err)
+	})
+}
+
+// TestMongoQueueDequeue_FindOneAndUpdateError checks that Dequeue returns an error when FindOneAndUpdate fails
+func TestMongoQueueDequeue_FindOneAndUpdateError(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		// Act
+		_, err := queue.Dequeue(context.Background(), time.Minute)
+
+		// Assert
+		assert.NotEmpty(mt, err)
+		assert.NotEqual(mt, NoJobsErr, err)
+	})
+}
+
+// TestMongoQueueComplete_Ok checks that Complete does not return an error when the job is updated
+func TestMongoQueueComplete_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		// Act
+		err := queue.Complete(context.Background(), &Job{ID: primitive.NewObjectID().Hex()})
+
+		// Assert
+		assert.Nil(mt, err)
+	})
+}
+
+// TestMongoQueueRetry_NotFound checks that Retry returns an error when the job does not exist
+func TestMongoQueueRetry_NotFound(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+
+		// Act
+		err := queue.Retry(context.Background(), &Job{ID: primitive.NewObjectID().Hex()}, time.Now(), errors.New("test-error"))
+
+		// Assert
+		assert.True(mt, errors.Is(err, wrappers.NonExistentErr))
+	})
+}
+
+// TestMongoQueueBury_InvalidID checks that Bury returns an error when the ID is not a valid ObjectID
+func TestMongoQueueBury_InvalidID(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+
+		// Act
+		err := queue.Bury(context.Background(), &Job{ID: "invalid-id"}, errors.New("test-error"))
+
+		// Assert
+		assert.NotEmpty(mt, err)
+	})
+}
+
+// TestMongoQueueBury_UpdateOneError checks that Bury returns an error when UpdateOne fails
+func TestMongoQueueBury_UpdateOneError(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		queue := NewMongoQueue(mt.DB.Collection(testCollectionName))
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		// Act
+		err := queue.Bury(context.Background(), &Job{ID: primitive.NewObjectID().Hex()}, errors.New("test-error"))
+
+		// Assert
+		assert.NotEmpty(mt, err)
+	})
+}
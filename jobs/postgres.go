@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+)
+
+// PostgresQueue is an implementation of the Queue interface backed by a PostgreSQL table
+// Jobs are leased with SELECT ... FOR UPDATE SKIP LOCKED, so that concurrent workers never get the same job
+type PostgresQueue struct {
+	db    *sql.DB
+	table string
+}
+
+// NewPostgresQueue creates a new queue storing the jobs in the given table
+func NewPostgresQueue(db *sql.DB, table string) *PostgresQueue {
+	return &PostgresQueue{
+		db:    db,
+		table: table,
+	}
+}
+
+// EnsureSchema creates the jobs table and its index when they do not exist
+func (q *PostgresQueue) EnsureSchema(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			id BIGSERIAL PRIMARY KEY,
+			type TEXT NOT NULL,
+			payload BYTEA,
+			status TEXT NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			max_attempts INT NOT NULL,
+			run_at TIMESTAMPTZ NOT NULL,
+			locked_until TIMESTAMPTZ,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS %[2]s ON %[1]s (status, run_at);`,
+		pq.QuoteIdentifier(q.table), pq.QuoteIdentifier(q.table+"_status_run_at_idx")))
+	return err
+}
+
+// Enqueue adds the job to the table
+func (q *PostgresQueue) Enqueue(ctx context.Context, job Job) (string, error) {
+	job = prepare(job)
+
+	var ID int64
+	err := q.db.QueryRowContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (type, payload, status, attempts, max_attempts, run_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, pq.QuoteIdentifier(q.table)),
+		job.Type, job.Payload, job.Status, job.Attempts, job.MaxAttempts, job.RunAt, job.CreatedAt,
+	).Scan(&ID)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(ID, 10), nil
+}
+
+// Dequeue leases the job ready to be run with the earliest RunAt, returning NoJobsErr when there is none
+// Running jobs whose lease has expired are considered abandoned and are leased again
+func (q *PostgresQueue) Dequeue(ctx context.Context, lease time.Duration) (*Job, error) {
+	now := time.Now()
+
+	var job Job
+	var ID int64
+	err := q.db.QueryRowContext(ctx, fmt.Sprintf(
+		`UPDATE %[1]s SET status = $1, attempts = attempts + 1, locked_until = $2
+		WHERE id = (
+			SELECT id FROM %[1]s
+			WHERE (status = $3 AND run_at <= $4) OR (status = $1 AND locked_until < $4)
+			ORDER BY run_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id, type, payload, status, attempts, max_attempts, run_at, last_error, created_at`, pq.QuoteIdentifier(q.table)),
+		StatusRunning, now.Add(lease), StatusPending, now,
+	).Scan(&ID, &job.Type, &job.Payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt, &job.LastError, &job.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NoJobsErr
+		}
+		return nil, err
+	}
+
+	job.ID = strconv.FormatInt(ID, 10)
+	return &job, nil
+}
+
+// Complete marks the job as done
+func (q *PostgresQueue) Complete(ctx context.Context, job *Job) error {
+	return q.update(ctx, job.ID, "status = $2, locked_until = NULL", StatusDone)
+}
+
+// Retry schedules the job to be run again at the given time
+func (q *PostgresQueue) Retry(ctx context.Context, job *Job, runAt time.Time, cause error) error {
+	return q.update(ctx, job.ID, "status = $2, run_at = $3, last_error = $4, locked_until = NULL", StatusPending, runAt, errorMessage(cause))
+}
+
+// Bury dead-letters the job
+func (q *PostgresQueue) Bury(ctx context.Context, job *Job, cause error) error {
+	return q.update(ctx, job.ID, "status = $2, last_error = $3, locked_until = NULL", StatusDead, errorMessage(cause))
+}
+
+func (q *PostgresQueue) update(ctx context.Context, ID string, set string, args ...interface{}) error {
+	result, err := q.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE id = $1", pq.QuoteIdentifier(q.table), set), append([]interface{}{ID}, args...)...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows < 1 {
+		return wrappers.NewNonExistentErr(errNotFound(ID))
+	}
+	return nil
+}
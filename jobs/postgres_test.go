@@ -0,0 +1,189 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sergicanet9/scv-go-tools/v4/mocks"
+	"github.com/sergicanet9/scv-go-tools/v4/wrappers"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTableName = "jobs"
+
+// TestPostgresQueueEnsureSchema_Ok checks that EnsureSchema creates the jobs table
+func TestPostgresQueueEnsureSchema_Ok(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS "jobs"`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Act
+	err := queue.EnsureSchema(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresQueueEnqueue_Ok checks that Enqueue inserts the job and returns its ID
+func TestPostgresQueueEnqueue_Ok(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectQuery(`INSERT INTO "jobs"`).
+		WithArgs("test", []byte("{}"), StatusPending, 0, defaultMaxAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Act
+	ID, err := queue.Enqueue(context.Background(), Job{Type: "test", Payload: []byte("{}")})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "1", ID)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresQueueEnqueue_InsertError checks that Enqueue returns an error when the insert fails
+func TestPostgresQueueEnqueue_InsertError(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectQuery(`INSERT INTO "jobs"`).WillReturnError(errors.New("test-error"))
+
+	// Act
+	_, err := queue.Enqueue(context.Background(), Job{Type: "test"})
+
+	// Assert
+	assert.Equal(t, "test-error", err.Error())
+}
+
+// TestPostgresQueueDequeue_Ok checks that Dequeue leases the next job skipping the locked ones
+func TestPostgresQueueDequeue_Ok(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE "jobs" SET status = \$1, attempts = attempts \+ 1(.|\n)*FOR UPDATE SKIP LOCKED`).
+		WithArgs(StatusRunning, sqlmock.AnyArg(), StatusPending, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "payload", "status", "attempts", "max_attempts", "run_at", "last_error", "created_at"}).
+			AddRow(1, "test", []byte("{}"), "running", 1, 3, now, "", now))
+
+	// Act
+	job, err := queue.Dequeue(context.Background(), time.Minute)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "1", job.ID)
+	assert.Equal(t, "test", job.Type)
+	assert.Equal(t, StatusRunning, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresQueueDequeue_NoJobs checks that Dequeue returns NoJobsErr when there are no jobs ready
+func TestPostgresQueueDequeue_NoJobs(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectQuery(`UPDATE "jobs"`).WillReturnError(sql.ErrNoRows)
+
+	// Act
+	_, err := queue.Dequeue(context.Background(), time.Minute)
+
+	// Assert
+	assert.Equal(t, NoJobsErr, err)
+}
+
+// TestPostgresQueueDequeue_QueryError checks that Dequeue returns an error when the query fails
+func TestPostgresQueueDequeue_QueryError(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectQuery(`UPDATE "jobs"`).WillReturnError(errors.New("test-error"))
+
+	// Act
+	_, err := queue.Dequeue(context.Background(), time.Minute)
+
+	// Assert
+	assert.Equal(t, "test-error", err.Error())
+}
+
+// TestPostgresQueueComplete_Ok checks that Complete marks the job as done
+func TestPostgresQueueComplete_Ok(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectExec(`UPDATE "jobs" SET status = \$2, locked_until = NULL WHERE id = \$1`).
+		WithArgs("1", StatusDone).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	err := queue.Complete(context.Background(), &Job{ID: "1"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresQueueRetry_Ok checks that Retry schedules the job again
+func TestPostgresQueueRetry_Ok(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+	runAt := time.Now()
+
+	mock.ExpectExec(`UPDATE "jobs" SET status = \$2, run_at = \$3, last_error = \$4`).
+		WithArgs("1", StatusPending, runAt, "test-error").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	err := queue.Retry(context.Background(), &Job{ID: "1"}, runAt, errors.New("test-error"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresQueueBury_NotFound checks that Bury returns an error when the job does not exist
+func TestPostgresQueueBury_NotFound(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectExec(`UPDATE "jobs" SET status = \$2, last_error = \$3`).
+		WithArgs("1", StatusDead, "test-error").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Act
+	err := queue.Bury(context.Background(), &Job{ID: "1"}, errors.New("test-error"))
+
+	// Assert
+	assert.True(t, errors.Is(err, wrappers.NonExistentErr))
+}
+
+// TestPostgresQueueBury_ExecError checks that Bury returns an error when the update fails
+func TestPostgresQueueBury_ExecError(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	queue := NewPostgresQueue(db, testTableName)
+
+	mock.ExpectExec(`UPDATE "jobs"`).WillReturnError(errors.New("test-error"))
+
+	// Act
+	err := queue.Bury(context.Background(), &Job{ID: "1"}, nil)
+
+	// Assert
+	assert.Equal(t, "test-error", err.Error())
+}
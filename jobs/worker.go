@@ -0,0 +1,165 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+)
+
+const (
+	defaultConcurrency   = 1
+	defaultPollInterval  = time.Second
+	defaultLeaseDuration = 5 * time.Minute
+	stateUpdateTimeout   = 10 * time.Second
+)
+
+// Handler runs a job, which is retried when an error is returned
+type Handler func(ctx context.Context, job Job) error
+
+// Worker runs the jobs of a queue with the handler registered for their type
+type Worker struct {
+	// Concurrency is the maximum number of jobs run at the same time
+	Concurrency int
+	// PollInterval is the time waited before polling the queue again when it is empty
+	PollInterval time.Duration
+	// LeaseDuration is the time after which a running job is considered abandoned and can be leased again
+	LeaseDuration time.Duration
+	// Backoff returns the delay before retrying a job that failed for the given attempt
+	Backoff func(attempt int) time.Duration
+
+	queue    Queue
+	handlers map[string]Handler
+}
+
+// NewWorker creates a new worker for the given queue
+func NewWorker(queue Queue) *Worker {
+	return &Worker{
+		Concurrency:   defaultConcurrency,
+		PollInterval:  defaultPollInterval,
+		LeaseDuration: defaultLeaseDuration,
+		Backoff:       ExponentialBackoff(time.Second, time.Hour),
+		queue:         queue,
+		handlers:      make(map[string]Handler),
+	}
+}
+
+// ExponentialBackoff returns a backoff function doubling the base delay on every attempt, up to max
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := float64(base) * math.Pow(2, float64(attempt-1))
+		if delay > float64(max) {
+			return max
+		}
+		return time.Duration(delay)
+	}
+}
+
+// Handle registers the handler for the given job type
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Run polls the queue and runs the jobs until the context is done, waiting for the running jobs before returning
+func (w *Worker) Run(ctx context.Context) error {
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case slots <- struct{}{}:
+		}
+
+		job, err := w.queue.Dequeue(ctx, w.LeaseDuration)
+		if err != nil {
+			<-slots
+			if !errors.Is(err, NoJobsErr) && ctx.Err() == nil {
+				observability.Logger().Printf("failed to dequeue job: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(w.PollInterval):
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			w.process(ctx, job)
+		}()
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *Job) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		w.bury(ctx, job, fmt.Errorf("no handler registered for job type %s", job.Type))
+		return
+	}
+
+	start := time.Now()
+	err := runHandler(ctx, handler, *job)
+	latency := time.Since(start)
+
+	if err == nil {
+		observability.Logger().Printf("Job: %s %s - Attempt: %d - Status: done - Latency: %s", job.Type, job.ID, job.Attempts, latency)
+		stateCtx, cancel := stateContext(ctx)
+		defer cancel()
+		if err := w.queue.Complete(stateCtx, job); err != nil {
+			observability.Logger().Printf("failed to complete job %s %s: %v", job.Type, job.ID, err)
+		}
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		w.bury(ctx, job, err)
+		return
+	}
+
+	runAt := time.Now().Add(w.Backoff(job.Attempts))
+	observability.Logger().Printf("Job: %s %s - Attempt: %d - Status: failed - Latency: %s - Error: %v - Retry at: %s", job.Type, job.ID, job.Attempts, latency, err, runAt)
+	stateCtx, cancel := stateContext(ctx)
+	defer cancel()
+	if err := w.queue.Retry(stateCtx, job, runAt, err); err != nil {
+		observability.Logger().Printf("failed to retry job %s %s: %v", job.Type, job.ID, err)
+	}
+}
+
+func (w *Worker) bury(ctx context.Context, job *Job, cause error) {
+	observability.Logger().Printf("Job: %s %s - Attempt: %d - Status: dead - Error: %v", job.Type, job.ID, job.Attempts, cause)
+	stateCtx, cancel := stateContext(ctx)
+	defer cancel()
+	if err := w.queue.Bury(stateCtx, job, cause); err != nil {
+		observability.Logger().Printf("failed to bury job %s %s: %v", job.Type, job.ID, err)
+	}
+}
+
+// stateContext returns a context for updating the state of a job that is not cancelled along with the worker,
+// so that a job finished during a graceful shutdown is not left running until its lease expires and then run again
+func stateContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), stateUpdateTimeout)
+}
+
+func runHandler(ctx context.Context, handler Handler, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered from panic during job %s %s, Panic: %v", job.Type, job.ID, r)
+		}
+	}()
+	return handler(ctx, job)
+}
@@ -0,0 +1,211 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWorker(queue Queue) *Worker {
+	worker := NewWorker(queue)
+	worker.PollInterval = time.Millisecond
+	worker.Backoff = func(attempt int) time.Duration { return 0 }
+	return worker
+}
+
+func runWorker(t *testing.T, worker *Worker, until func() bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- worker.Run(ctx) }()
+
+	assert.Eventually(t, until, time.Second, time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+// TestExponentialBackoff_Ok checks that ExponentialBackoff doubles the delay on every attempt up to the max
+func TestExponentialBackoff_Ok(t *testing.T) {
+	// Arrange
+	backoff := ExponentialBackoff(time.Second, 5*time.Second)
+
+	// Act & Assert
+	assert.Equal(t, time.Second, backoff(1))
+	assert.Equal(t, 2*time.Second, backoff(2))
+	assert.Equal(t, 4*time.Second, backoff(3))
+	assert.Equal(t, 5*time.Second, backoff(4))
+}
+
+// TestWorkerRun_Ok checks that the worker runs the job with its handler and marks it as done
+func TestWorkerRun_Ok(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	job, _ := NewJob("test", "test-payload")
+	queue.Enqueue(context.Background(), job)
+
+	var payload string
+	worker := newTestWorker(queue)
+	worker.Handle("test", func(ctx context.Context, job Job) error {
+		return job.Decode(&payload)
+	})
+
+	// Act
+	runWorker(t, worker, func() bool { return queue.Jobs()[0].Status == StatusDone })
+
+	// Assert
+	assert.Equal(t, "test-payload", payload)
+}
+
+// TestWorkerRun_Retry checks that the worker retries a failing job until it succeeds
+func TestWorkerRun_Retry(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "test"})
+
+	var calls int32
+	worker := newTestWorker(queue)
+	worker.Handle("test", func(ctx context.Context, job Job) error {
+		if atomic.AddInt32(&calls, 1) < 2 {
+			return errors.New("test-error")
+		}
+		return nil
+	})
+
+	// Act
+	runWorker(t, worker, func() bool { return queue.Jobs()[0].Status == StatusDone })
+
+	// Assert
+	stored := queue.Jobs()[0]
+	assert.Equal(t, 2, stored.Attempts)
+	assert.Equal(t, "test-error", stored.LastError)
+}
+
+// TestWorkerRun_DeadLetter checks that the worker dead-letters a job that exhausted its attempts
+func TestWorkerRun_DeadLetter(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "test", MaxAttempts: 2})
+
+	worker := newTestWorker(queue)
+	worker.Handle("test", func(ctx context.Context, job Job) error {
+		return errors.New("test-error")
+	})
+
+	// Act
+	runWorker(t, worker, func() bool { return queue.Jobs()[0].Status == StatusDead })
+
+	// Assert
+	stored := queue.Jobs()[0]
+	assert.Equal(t, 2, stored.Attempts)
+	assert.Equal(t, "test-error", stored.LastError)
+}
+
+// TestWorkerRun_Panic checks that the worker recovers from a panic in the handler and treats it as a failure
+func TestWorkerRun_Panic(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	ID, _ := queue.Enqueue(context.Background(), Job{Type: "test", MaxAttempts: 1})
+	expectedError := "recovered from panic during job test " + ID + ", Panic: test panic"
+
+	worker := newTestWorker(queue)
+	worker.Handle("test", func(ctx context.Context, job Job) error {
+		panic("test panic")
+	})
+
+	// Act
+	runWorker(t, worker, func() bool { return queue.Jobs()[0].Status == StatusDead })
+
+	// Assert
+	assert.Equal(t, expectedError, queue.Jobs()[0].LastError)
+}
+
+// TestWorkerRun_UnknownType checks that the worker dead-letters a job without a registered handler
+func TestWorkerRun_UnknownType(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "unknown"})
+	worker := newTestWorker(queue)
+
+	// Act
+	runWorker(t, worker, func() bool { return queue.Jobs()[0].Status == StatusDead })
+
+	// Assert
+	assert.Equal(t, "no handler registered for job type unknown", queue.Jobs()[0].LastError)
+}
+
+// TestWorkerRun_Concurrency checks that the worker does not run more jobs at the same time than its Concurrency
+func TestWorkerRun_Concurrency(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	for i := 0; i < 6; i++ {
+		queue.Enqueue(context.Background(), Job{Type: "test"})
+	}
+
+	var running, maxRunning, done int32
+	worker := newTestWorker(queue)
+	worker.Concurrency = 2
+	worker.Handle("test", func(ctx context.Context, job Job) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&done, 1)
+		return nil
+	})
+
+	// Act
+	runWorker(t, worker, func() bool { return atomic.LoadInt32(&done) == 6 })
+
+	// Assert
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+}
+
+// cancellableQueue is a MemoryQueue failing to update the state of the jobs when the context is done, like the db-backed queues
+type cancellableQueue struct {
+	*MemoryQueue
+}
+
+func (q cancellableQueue) Complete(ctx context.Context, job *Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return q.MemoryQueue.Complete(ctx, job)
+}
+
+// TestWorkerRun_Shutdown checks that the worker marks as done a job that finishes after the context is cancelled
+func TestWorkerRun_Shutdown(t *testing.T) {
+	// Arrange
+	queue := NewMemoryQueue()
+	queue.Enqueue(context.Background(), Job{Type: "test"})
+
+	started := make(chan struct{})
+	worker := newTestWorker(cancellableQueue{queue})
+	worker.Handle("test", func(ctx context.Context, job Job) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- worker.Run(ctx) }()
+	<-started
+
+	// Act
+	cancel()
+	err := <-done
+
+	// Assert
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, StatusDone, queue.Jobs()[0].Status)
+}
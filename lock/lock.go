@@ -0,0 +1,83 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const defaultRetryInterval = 500 * time.Millisecond
+
+// ErrLeaseLost is returned when a lease can no longer be renewed or released because the lock is not held anymore
+var ErrLeaseLost = errors.New("lock lease lost")
+
+// Locker interface to be used as a port for acquiring named locks shared between replicas
+type Locker interface {
+	// TryLock tries to acquire the lock once, returning false when it is already held by someone else
+	TryLock(ctx context.Context, key string) (Lease, bool, error)
+	// Lock blocks until the lock is acquired or the context is done
+	Lock(ctx context.Context, key string) (Lease, error)
+}
+
+// ExpiringLocker is a Locker whose leases expire unless they are renewed within their ttl
+type ExpiringLocker interface {
+	Locker
+	// TTL returns the time after which a lease expires unless renewed, zero meaning that it never expires
+	TTL() time.Duration
+}
+
+// Lease is a lock held by the current process
+type Lease interface {
+	// Key returns the name of the lock
+	Key() string
+	// Renew extends the lease of the lock, returning ErrLeaseLost when it is not held anymore
+	Renew(ctx context.Context) error
+	// Unlock releases the lock, returning ErrLeaseLost when it is not held anymore
+	Unlock(ctx context.Context) error
+}
+
+// KeepAlive renews the lease periodically with the given interval until the context is done or a renewal fails,
+// which makes it suitable for leader election: the returned channel is closed as soon as the lease is lost
+func KeepAlive(ctx context.Context, lease Lease, interval time.Duration) <-chan struct{} {
+	lost := make(chan struct{})
+	go func() {
+		defer close(lost)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := lease.Renew(ctx); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return lost
+}
+
+func lockWithRetry(ctx context.Context, locker Locker, key string, retryInterval time.Duration) (Lease, error) {
+	if retryInterval <= 0 {
+		retryInterval = defaultRetryInterval
+	}
+
+	for {
+		lease, ok, err := locker.TryLock(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return lease, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingLocker struct{}
+
+func (failingLocker) TryLock(ctx context.Context, key string) (Lease, bool, error) {
+	return nil, false, errors.New("test-error")
+}
+
+func (failingLocker) Lock(ctx context.Context, key string) (Lease, error) {
+	return nil, errors.New("test-error")
+}
+
+// TestKeepAlive_LeaseLost checks that KeepAlive closes the returned channel when the lease cannot be renewed
+func TestKeepAlive_LeaseLost(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(0)
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+	lease.Unlock(context.Background())
+
+	// Act
+	lost := KeepAlive(context.Background(), lease, time.Millisecond)
+
+	// Assert
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("expected the lease to be lost")
+	}
+}
+
+// TestKeepAlive_ContextDone checks that KeepAlive renews the lease until the context is done
+func TestKeepAlive_ContextDone(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(20 * time.Millisecond)
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	lost := KeepAlive(ctx, lease, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	// Assert
+	_, acquired, _ := locker.TryLock(context.Background(), "test")
+	assert.False(t, acquired)
+	cancel()
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("expected KeepAlive to stop")
+	}
+}
+
+// TestLockWithRetry_Error checks that lockWithRetry returns the error of TryLock
+func TestLockWithRetry_Error(t *testing.T) {
+	// Act
+	_, err := lockWithRetry(context.Background(), failingLocker{}, "test", 0)
+
+	// Assert
+	assert.Equal(t, "test-error", err.Error())
+}
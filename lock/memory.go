@@ -0,0 +1,106 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLocker is an in-memory implementation of the Locker interface, intended for tests and single replica services
+type MemoryLocker struct {
+	// RetryInterval is the time waited by Lock between acquisition attempts
+	RetryInterval time.Duration
+
+	ttl   time.Duration
+	mu    sync.Mutex
+	locks map[string]memoryEntry
+	seq   uint64
+}
+
+type memoryEntry struct {
+	owner     uint64
+	expiresAt time.Time
+}
+
+type memoryLease struct {
+	locker *MemoryLocker
+	key    string
+	owner  uint64
+}
+
+// NewMemoryLocker creates a new in-memory locker whose leases expire after the given ttl, or never when ttl is zero
+func NewMemoryLocker(ttl time.Duration) *MemoryLocker {
+	return &MemoryLocker{
+		ttl:   ttl,
+		locks: make(map[string]memoryEntry),
+	}
+}
+
+// TryLock tries to acquire the lock once, returning false when it is already held by someone else
+func (l *MemoryLocker) TryLock(ctx context.Context, key string) (Lease, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.locks[key]; ok && !l.expired(entry) {
+		return nil, false, nil
+	}
+
+	l.seq++
+	l.locks[key] = memoryEntry{owner: l.seq, expiresAt: l.expiration()}
+	return &memoryLease{locker: l, key: key, owner: l.seq}, true, nil
+}
+
+// Lock blocks until the lock is acquired or the context is done
+func (l *MemoryLocker) Lock(ctx context.Context, key string) (Lease, error) {
+	return lockWithRetry(ctx, l, key, l.RetryInterval)
+}
+
+// TTL returns the time after which a lease expires unless renewed, zero meaning that it never expires
+func (l *MemoryLocker) TTL() time.Duration {
+	return l.ttl
+}
+
+func (l *MemoryLocker) expiration() time.Time {
+	if l.ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(l.ttl)
+}
+
+func (l *MemoryLocker) expired(entry memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)
+}
+
+// Key returns the name of the lock
+func (m *memoryLease) Key() string {
+	return m.key
+}
+
+// Renew extends the lease of the lock by the ttl of the locker
+func (m *memoryLease) Renew(ctx context.Context) error {
+	m.locker.mu.Lock()
+	defer m.locker.mu.Unlock()
+
+	entry, ok := m.locker.locks[m.key]
+	if !ok || entry.owner != m.owner || m.locker.expired(entry) {
+		return ErrLeaseLost
+	}
+
+	entry.expiresAt = m.locker.expiration()
+	m.locker.locks[m.key] = entry
+	return nil
+}
+
+// Unlock releases the lock
+func (m *memoryLease) Unlock(ctx context.Context) error {
+	m.locker.mu.Lock()
+	defer m.locker.mu.Unlock()
+
+	entry, ok := m.locker.locks[m.key]
+	if !ok || entry.owner != m.owner {
+		return ErrLeaseLost
+	}
+
+	delete(m.locker.locks, m.key)
+	return nil
+}
@@ -0,0 +1,122 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryLockerTryLock_Ok checks that TryLock acquires a free lock
+func TestMemoryLockerTryLock_Ok(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(0)
+
+	// Act
+	lease, acquired, err := locker.TryLock(context.Background(), "test")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "test", lease.Key())
+}
+
+// TestMemoryLockerTryLock_AlreadyHeld checks that TryLock does not acquire a lock held by someone else
+func TestMemoryLockerTryLock_AlreadyHeld(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(0)
+	locker.TryLock(context.Background(), "test")
+
+	// Act
+	lease, acquired, err := locker.TryLock(context.Background(), "test")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	assert.Nil(t, lease)
+}
+
+// TestMemoryLockerTryLock_Expired checks that TryLock takes over a lock whose lease has expired
+func TestMemoryLockerTryLock_Expired(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(time.Millisecond)
+	oldLease, _, _ := locker.TryLock(context.Background(), "test")
+	time.Sleep(5 * time.Millisecond)
+
+	// Act
+	_, acquired, err := locker.TryLock(context.Background(), "test")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, ErrLeaseLost, oldLease.Renew(context.Background()))
+	assert.Equal(t, ErrLeaseLost, oldLease.Unlock(context.Background()))
+}
+
+// TestMemoryLockerLock_WaitsForUnlock checks that Lock blocks until the lock is released
+func TestMemoryLockerLock_WaitsForUnlock(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(0)
+	locker.RetryInterval = time.Millisecond
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		lease.Unlock(context.Background())
+	}()
+
+	// Act
+	newLease, err := locker.Lock(context.Background(), "test")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "test", newLease.Key())
+}
+
+// TestMemoryLockerLock_ContextDone checks that Lock returns an error when the context is done before acquiring the lock
+func TestMemoryLockerLock_ContextDone(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(0)
+	locker.RetryInterval = time.Millisecond
+	locker.TryLock(context.Background(), "test")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := locker.Lock(ctx, "test")
+
+	// Assert
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+// TestMemoryLeaseRenew_Ok checks that Renew extends the lease of a held lock
+func TestMemoryLeaseRenew_Ok(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(20 * time.Millisecond)
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+	time.Sleep(10 * time.Millisecond)
+
+	// Act
+	err := lease.Renew(context.Background())
+	time.Sleep(15 * time.Millisecond)
+
+	// Assert
+	assert.Nil(t, err)
+	_, acquired, _ := locker.TryLock(context.Background(), "test")
+	assert.False(t, acquired)
+}
+
+// TestMemoryLeaseUnlock_Ok checks that Unlock releases the lock so that it can be acquired again
+func TestMemoryLeaseUnlock_Ok(t *testing.T) {
+	// Arrange
+	locker := NewMemoryLocker(0)
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+
+	// Act
+	err := lease.Unlock(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	_, acquired, _ := locker.TryLock(context.Background(), "test")
+	assert.True(t, acquired)
+}
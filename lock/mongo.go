@@ -0,0 +1,104 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoLocker is an implementation of the Locker interface based on leases stored as documents in a MongoDB collection
+// Expired leases can be taken over by other replicas, and are eventually deleted by the TTL index created in EnsureIndexes
+type MongoLocker struct {
+	// RetryInterval is the time waited by Lock between acquisition attempts
+	RetryInterval time.Duration
+
+	collection *mongo.Collection
+	ttl        time.Duration
+}
+
+type mongoLease struct {
+	locker *MongoLocker
+	key    string
+	owner  string
+}
+
+// NewMongoLocker creates a new locker storing the leases in the given collection, which expire after the given ttl unless renewed
+func NewMongoLocker(collection *mongo.Collection, ttl time.Duration) *MongoLocker {
+	return &MongoLocker{
+		collection: collection,
+		ttl:        ttl,
+	}
+}
+
+// EnsureIndexes creates the TTL index that deletes the expired leases from the collection
+func (l *MongoLocker) EnsureIndexes(ctx context.Context) error {
+	_, err := l.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// TryLock tries to acquire the lease once, returning false when it is already held by someone else
+func (l *MongoLocker) TryLock(ctx context.Context, key string) (Lease, bool, error) {
+	owner := primitive.NewObjectID().Hex()
+	now := time.Now()
+
+	filter := bson.M{"_id": key, "expires_at": bson.M{"$lt": now}}
+	update := bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(l.ttl)}}
+	_, err := l.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return &mongoLease{locker: l, key: key, owner: owner}, true, nil
+}
+
+// Lock blocks until the lease is acquired or the context is done
+func (l *MongoLocker) Lock(ctx context.Context, key string) (Lease, error) {
+	return lockWithRetry(ctx, l, key, l.RetryInterval)
+}
+
+// TTL returns the time after which a lease expires unless renewed
+func (l *MongoLocker) TTL() time.Duration {
+	return l.ttl
+}
+
+// Key returns the name of the lock
+func (m *mongoLease) Key() string {
+	return m.key
+}
+
+// Renew extends the lease by the ttl of the locker
+func (m *mongoLease) Renew(ctx context.Context) error {
+	filter := bson.M{"_id": m.key, "owner": m.owner}
+	update := bson.M{"$set": bson.M{"expires_at": time.Now().Add(m.locker.ttl)}}
+	result, err := m.locker.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount < 1 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Unlock releases the lease
+func (m *mongoLease) Unlock(ctx context.Context) error {
+	filter := bson.M{"_id": m.key, "owner": m.owner}
+	result, err := m.locker.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount < 1 {
+		return ErrLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sergicanet9/scv-go-tools/v4/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const testCollectionName = "locks"
+
+// TestMongoLockerEnsureIndexes_Ok checks that EnsureIndexes does not return an error when the index is created
+func TestMongoLockerEnsureIndexes_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		// Act
+		err := locker.EnsureIndexes(context.Background())
+
+		// Assert
+		assert.Nil(mt, err)
+	})
+}
+
+// TestMongoLockerTryLock_Ok checks that TryLock returns a lease when the lock is acquired
+func TestMongoLockerTryLock_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		// Act
+		lease, acquired, err := locker.TryLock(context.Background(), "test")
+
+		// Assert
+		assert.Nil(mt, err)
+		assert.True(mt, acquired)
+		assert.Equal(mt, "test", lease.Key())
+	})
+}
+
+// TestMongoLockerTryLock_AlreadyHeld checks that TryLock returns false when the lease is held by someone else
+func TestMongoLockerTryLock_AlreadyHeld(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key error"}))
+
+		// Act
+		lease, acquired, err := locker.TryLock(context.Background(), "test")
+
+		// Assert
+		assert.Nil(mt, err)
+		assert.False(mt, acquired)
+		assert.Nil(mt, lease)
+	})
+}
+
+// TestMongoLockerTryLock_UpdateError checks that TryLock returns an error when UpdateOne fails
+func TestMongoLockerTryLock_UpdateError(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		// Act
+		_, _, err := locker.TryLock(context.Background(), "test")
+
+		// Assert
+		assert.NotEmpty(mt, err)
+	})
+}
+
+// TestMongoLeaseRenew_Ok checks that Renew does not return an error when the lease is still held
+func TestMongoLeaseRenew_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+		lease, _, _ := locker.TryLock(context.Background(), "test")
+
+		// Act
+		err := lease.Renew(context.Background())
+
+		// Assert
+		assert.Nil(mt, err)
+	})
+}
+
+// TestMongoLeaseRenew_LeaseLost checks that Renew returns ErrLeaseLost when the lease was taken over
+func TestMongoLeaseRenew_LeaseLost(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+		)
+		lease, _, _ := locker.TryLock(context.Background(), "test")
+
+		// Act
+		err := lease.Renew(context.Background())
+
+		// Assert
+		assert.Equal(mt, ErrLeaseLost, err)
+	})
+}
+
+// TestMongoLeaseUnlock_Ok checks that Unlock does not return an error when the lease is deleted
+func TestMongoLeaseUnlock_Ok(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+		lease, _, _ := locker.TryLock(context.Background(), "test")
+
+		// Act
+		err := lease.Unlock(context.Background())
+
+		// Assert
+		assert.Nil(mt, err)
+	})
+}
+
+// TestMongoLeaseUnlock_LeaseLost checks that Unlock returns ErrLeaseLost when the lease was not found
+func TestMongoLeaseUnlock_LeaseLost(t *testing.T) {
+	mt := mocks.NewMongoDB(t)
+
+	mt.Run("", func(mt *mtest.T) {
+		// Arrange
+		locker := NewMongoLocker(mt.DB.Collection(testCollectionName), time.Minute)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+		)
+		lease, _, _ := locker.TryLock(context.Background(), "test")
+
+		// Act
+		err := lease.Unlock(context.Background())
+
+		// Assert
+		assert.Equal(mt, ErrLeaseLost, err)
+	})
+}
@@ -0,0 +1,98 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"time"
+)
+
+// PostgresLocker is an implementation of the Locker interface based on PostgreSQL session-level advisory locks
+// Each lease holds a dedicated connection of the pool until it is unlocked, and the lock is released by the db if the connection drops
+type PostgresLocker struct {
+	// RetryInterval is the time waited by Lock between acquisition attempts
+	RetryInterval time.Duration
+
+	db *sql.DB
+}
+
+type postgresLease struct {
+	conn *sql.Conn
+	key  string
+	id   int64
+}
+
+// NewPostgresLocker creates a new locker using the given db
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+// TryLock tries to acquire the advisory lock once, returning false when it is already held by someone else
+func (l *PostgresLocker) TryLock(ctx context.Context, key string) (Lease, bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	id := advisoryLockID(key)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&acquired); err != nil {
+		discard(conn)
+		return nil, false, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	return &postgresLease{conn: conn, key: key, id: id}, true, nil
+}
+
+// Lock blocks until the advisory lock is acquired or the context is done
+func (l *PostgresLocker) Lock(ctx context.Context, key string) (Lease, error) {
+	return lockWithRetry(ctx, l, key, l.RetryInterval)
+}
+
+// Key returns the name of the lock
+func (p *postgresLease) Key() string {
+	return p.key
+}
+
+// Renew checks that the connection holding the advisory lock is still alive, since the lock has no expiration
+func (p *postgresLease) Renew(ctx context.Context) error {
+	if err := p.conn.PingContext(ctx); err != nil {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Unlock releases the advisory lock and returns the connection to the pool
+// When the lock cannot be released, the connection is discarded instead so that the db releases it along with the session
+func (p *postgresLease) Unlock(ctx context.Context) error {
+	var released bool
+	if err := p.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", p.id).Scan(&released); err != nil {
+		discard(p.conn)
+		return err
+	}
+
+	p.conn.Close()
+	if !released {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// discard closes the physical connection instead of returning it to the pool, since its session may still hold the advisory lock
+func discard(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
+
+func advisoryLockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,146 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/sergicanet9/scv-go-tools/v4/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostgresLockerTryLock_Ok checks that TryLock returns a lease when the advisory lock is acquired
+func TestPostgresLockerTryLock_Ok(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	locker := NewPostgresLocker(db)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").
+		WithArgs(advisoryLockID("test")).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+
+	// Act
+	lease, acquired, err := locker.TryLock(context.Background(), "test")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "test", lease.Key())
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresLockerTryLock_AlreadyHeld checks that TryLock returns false when the advisory lock is held by someone else
+func TestPostgresLockerTryLock_AlreadyHeld(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	locker := NewPostgresLocker(db)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+
+	// Act
+	lease, acquired, err := locker.TryLock(context.Background(), "test")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	assert.Nil(t, lease)
+}
+
+// TestPostgresLockerTryLock_QueryError checks that TryLock returns an error when the query fails
+func TestPostgresLockerTryLock_QueryError(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	locker := NewPostgresLocker(db)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").WillReturnError(errors.New("test-error"))
+
+	// Act
+	_, _, err := locker.TryLock(context.Background(), "test")
+
+	// Assert
+	assert.Equal(t, "test-error", err.Error())
+}
+
+// TestPostgresLeaseUnlock_Ok checks that Unlock releases the advisory lock
+func TestPostgresLeaseUnlock_Ok(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	locker := NewPostgresLocker(db)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectQuery("SELECT pg_advisory_unlock").
+		WithArgs(advisoryLockID("test")).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_advisory_unlock"}).AddRow(true))
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+
+	// Act
+	err := lease.Unlock(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresLeaseUnlock_NotHeld checks that Unlock returns ErrLeaseLost when the advisory lock was not held
+func TestPostgresLeaseUnlock_NotHeld(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	locker := NewPostgresLocker(db)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectQuery("SELECT pg_advisory_unlock").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_advisory_unlock"}).AddRow(false))
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+
+	// Act
+	err := lease.Unlock(context.Background())
+
+	// Assert
+	assert.Equal(t, ErrLeaseLost, err)
+}
+
+// TestPostgresLeaseUnlock_QueryError checks that Unlock discards the connection instead of returning it to the pool when the advisory lock cannot be released
+func TestPostgresLeaseUnlock_QueryError(t *testing.T) {
+	// Arrange
+	mock, db := mocks.NewSqlDB(t)
+	locker := NewPostgresLocker(db)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectQuery("SELECT pg_advisory_unlock").WillReturnError(context.DeadlineExceeded)
+	mock.ExpectClose()
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+
+	// Act
+	err := lease.Unlock(context.Background())
+
+	// Assert
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 0, db.Stats().OpenConnections)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// TestPostgresLeaseRenew_ConnectionLost checks that Renew returns ErrLeaseLost when the connection holding the lock is not alive
+func TestPostgresLeaseRenew_ConnectionLost(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	locker := NewPostgresLocker(db)
+
+	mock.ExpectQuery("SELECT pg_try_advisory_lock").
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectPing().WillReturnError(errors.New("test-error"))
+	lease, _, _ := locker.TryLock(context.Background(), "test")
+
+	// Act
+	err = lease.Renew(context.Background())
+
+	// Assert
+	assert.Equal(t, ErrLeaseLost, err)
+}
@@ -0,0 +1,79 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type loggerCtxKey string
+
+// LoggerKey is the context key of the request-scoped logger, set by the HTTP logging middleware and the gRPC logging interceptors
+const LoggerKey loggerCtxKey = "logger"
+
+type contextLogger struct {
+	mu     sync.RWMutex
+	logger *slog.Logger
+}
+
+// ContextWithLogger returns a copy of the context holding the logger
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, LoggerKey, &contextLogger{logger: logger})
+}
+
+// LoggerFromContext returns the logger held by the context, or the singleton structured logger when there is none
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	holder, ok := ctx.Value(LoggerKey).(*contextLogger)
+	if !ok {
+		return Log()
+	}
+
+	holder.mu.RLock()
+	defer holder.mu.RUnlock()
+
+	return holder.logger
+}
+
+// AddLogAttrs enriches in place the logger held by the context with the given key-value pairs, so that they are also included
+// in the records of the callers that attached it, such as the summary of the logging middleware. It does nothing when the context holds no logger
+func AddLogAttrs(ctx context.Context, args ...any) {
+	holder, ok := ctx.Value(LoggerKey).(*contextLogger)
+	if !ok {
+		return
+	}
+
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+
+	holder.logger = holder.logger.With(args...)
+}
+
+// TraceIDs returns the trace and span IDs of the OpenTelemetry span or the New Relic transaction held by the context or, when there is none,
+// of the given W3C traceparent header, returning empty strings when none is available
+func TraceIDs(ctx context.Context, traceparent string) (traceID, spanID string) {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		return spanContext.TraceID().String(), spanContext.SpanID().String()
+	}
+	if metadata := newrelic.FromContext(ctx).GetTraceMetadata(); metadata.TraceID != "" {
+		return metadata.TraceID, metadata.SpanID
+	}
+
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
+
+// RequestLogger returns the singleton structured logger enriched with the request ID and the trace and span IDs when available
+func RequestLogger(ctx context.Context, requestID, traceparent string) *slog.Logger {
+	logger := Log().With(slog.String("request_id", requestID))
+	if traceID, spanID := TraceIDs(ctx, traceparent); traceID != "" {
+		logger = logger.With(slog.String("trace_id", traceID), slog.String("span_id", spanID))
+	}
+	return logger
+}
@@ -0,0 +1,107 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoggerFromContext_Fallback checks that the singleton structured logger is returned when the context holds no logger
+func TestLoggerFromContext_Fallback(t *testing.T) {
+	// Act
+	logger := LoggerFromContext(context.Background())
+
+	// Assert
+	assert.Equal(t, Log(), logger)
+}
+
+// TestLoggerFromContext_Ok checks that the logger held by the context is returned
+func TestLoggerFromContext_Ok(t *testing.T) {
+	// Arrange
+	expectedLogger := Log().With(slog.String("key", "value"))
+	ctx := ContextWithLogger(context.Background(), expectedLogger)
+
+	// Act
+	logger := LoggerFromContext(ctx)
+
+	// Assert
+	assert.Equal(t, expectedLogger, logger)
+}
+
+// TestAddLogAttrs checks that the attributes are added to the logger held by the context and visible from its parent contexts
+func TestAddLogAttrs(t *testing.T) {
+	// Arrange
+	buf := setupTestLogger(t)
+	ctx := ContextWithLogger(context.Background(), Log().With(slog.String("request_id", "test-request-id")))
+	childCtx := context.WithValue(ctx, "key", "value")
+
+	// Act
+	AddLogAttrs(childCtx, slog.String("subject", "test-subject"))
+	AddLogAttrs(context.Background(), slog.String("ignored", "value"))
+	LoggerFromContext(ctx).Info("test message")
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "test-request-id", record["request_id"])
+	assert.Equal(t, "test-subject", record["subject"])
+}
+
+// TestTraceIDs checks that the trace and span IDs are extracted from the traceparent header when valid
+func TestTraceIDs(t *testing.T) {
+	cases := []struct {
+		name            string
+		traceparent     string
+		expectedTraceID string
+		expectedSpanID  string
+	}{
+		{"Valid traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"},
+		{"Invalid traceparent", "00-4bf92f3577b34da6-01", "", ""},
+		{"Missing traceparent", "", "", ""},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			traceID, spanID := TraceIDs(context.Background(), tt.traceparent)
+
+			// Assert
+			assert.Equal(t, tt.expectedTraceID, traceID)
+			assert.Equal(t, tt.expectedSpanID, spanID)
+		})
+	}
+}
+
+// TestRequestLogger checks that the logger carries the request ID and the trace IDs when available
+func TestRequestLogger(t *testing.T) {
+	// Arrange
+	buf := setupTestLogger(t)
+
+	// Act
+	RequestLogger(context.Background(), "test-request-id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01").Info("test message")
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "test-request-id", record["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+}
+
+// TestTraceIDs_Span checks that the trace and span IDs of the OpenTelemetry span held by the context take precedence over the traceparent header
+func TestTraceIDs_Span(t *testing.T) {
+	// Arrange
+	NewTracerProvider("test-service")
+	ctx, span := Tracer().Start(context.Background(), "test-span")
+	defer span.End()
+
+	// Act
+	traceID, spanID := TraceIDs(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Assert
+	assert.Equal(t, span.SpanContext().TraceID().String(), traceID)
+	assert.Equal(t, span.SpanContext().SpanID().String(), spanID)
+}
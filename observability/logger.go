@@ -1,17 +1,144 @@
 package observability
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"slices"
 	"sync"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogFormat is the output format of the structured logger
+type LogFormat string
+
+const (
+	// LogFormatText writes the records as key=value pairs
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes the records as JSON objects, one per line
+	LogFormatJSON LogFormat = "json"
 )
 
 var (
-	logger = log.New(os.Stdout, "", log.Default().Flags())
-	m      sync.Mutex
+	level   = new(slog.LevelVar)
+	format  = LogFormatText
+	slogger = newStructuredLogger(os.Stdout, format)
+	logger  = slog.NewLogLogger(slogger.Handler(), slog.LevelInfo)
+	m       sync.RWMutex
 )
 
-// Logger returns the singleton logger instance
+// Logger returns the singleton logger instance, which writes its lines as info records through the structured logger
 func Logger() *log.Logger {
+	m.RLock()
+	defer m.RUnlock()
+
 	return logger
 }
+
+// Log returns the singleton structured logger instance
+func Log() *slog.Logger {
+	m.RLock()
+	defer m.RUnlock()
+
+	return slogger
+}
+
+// SetupLogger configures the output and the format of the singleton loggers
+func SetupLogger(w io.Writer, f LogFormat) error {
+	if f != LogFormatText && f != LogFormatJSON {
+		return fmt.Errorf("unsupported log format %s", f)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	format = f
+	setOutput(w)
+	return nil
+}
+
+// SetLogLevel changes at runtime the minimum level of the records written by the structured logger
+func SetLogLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// LogLevel returns the current minimum level of the records written by the structured logger
+func LogLevel() slog.Level {
+	return level.Level()
+}
+
+// NewRequestID generates a new random request ID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// setOutput replaces the singleton loggers with new ones writing to w, the caller must hold the lock
+func setOutput(w io.Writer) {
+	slogger = newStructuredLogger(w, format)
+	logger = slog.NewLogLogger(slogger.Handler(), slog.LevelInfo)
+}
+
+func newStructuredLogger(w io.Writer, f LogFormat) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if f == LogFormatJSON {
+		return slog.New(&traceHandler{Handler: slog.NewJSONHandler(w, options)})
+	}
+	return slog.New(&traceHandler{Handler: slog.NewTextHandler(w, options)})
+}
+
+// traceHandler adds the trace and span IDs to the records. The IDs the logger was enriched with are kept apart from the other attributes,
+// so that enriching it again with the IDs of a new span replaces them, and otherwise the IDs of the OpenTelemetry span held by the context
+// of the records are added
+type traceHandler struct {
+	slog.Handler
+	traceIDs []slog.Attr
+	grouped  bool
+}
+
+func (h *traceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if len(h.traceIDs) > 0 {
+		record.AddAttrs(h.traceIDs...)
+	} else if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if h.grouped {
+		return &traceHandler{Handler: h.Handler.WithAttrs(attrs), traceIDs: h.traceIDs, grouped: true}
+	}
+
+	traceIDs := slices.Clone(h.traceIDs)
+	var others []slog.Attr
+	for _, attr := range attrs {
+		if attr.Key != "trace_id" && attr.Key != "span_id" {
+			others = append(others, attr)
+			continue
+		}
+		index := slices.IndexFunc(traceIDs, func(a slog.Attr) bool { return a.Key == attr.Key })
+		if index >= 0 {
+			traceIDs[index] = attr
+		} else {
+			traceIDs = append(traceIDs, attr)
+		}
+	}
+
+	handler := h.Handler
+	if len(others) > 0 {
+		handler = handler.WithAttrs(others)
+	}
+	return &traceHandler{Handler: handler, traceIDs: traceIDs}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{Handler: h.Handler.WithGroup(name), traceIDs: h.traceIDs, grouped: true}
+}
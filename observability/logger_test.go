@@ -1,8 +1,15 @@
 package observability
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,3 +24,142 @@ func TestLogger_ReturnsSingleton(t *testing.T) {
 	assert.NotNil(t, logger2)
 	assert.Equal(t, logger1, logger2)
 }
+
+// setupTestLogger configures the singleton loggers to write JSON records to the returned buffer until the test finishes
+func setupTestLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	SetupLogger(buf, LogFormatJSON)
+	t.Cleanup(func() {
+		SetupLogger(os.Stdout, LogFormatText)
+		SetLogLevel(slog.LevelInfo)
+	})
+	return buf
+}
+
+// TestLog_JSONFormat checks that the structured logger writes the records with their fields as JSON
+func TestLog_JSONFormat(t *testing.T) {
+	// Arrange
+	buf := setupTestLogger(t)
+
+	// Act
+	Log().Info("test message", slog.String("key", "value"), slog.Int("count", 3))
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "test message", record["msg"])
+	assert.Equal(t, "value", record["key"])
+	assert.Equal(t, float64(3), record["count"])
+}
+
+// TestLog_TextFormat checks that the structured logger writes the records with their fields as key=value pairs
+func TestLog_TextFormat(t *testing.T) {
+	// Arrange
+	setupTestLogger(t)
+	buf := &bytes.Buffer{}
+	SetupLogger(buf, LogFormatText)
+
+	// Act
+	Log().Warn("test message", slog.String("key", "value"))
+
+	// Assert
+	assert.Contains(t, buf.String(), `level=WARN msg="test message" key=value`)
+}
+
+// TestSetLogLevel checks that the records below the level set at runtime are discarded
+func TestSetLogLevel(t *testing.T) {
+	// Arrange
+	buf := setupTestLogger(t)
+
+	// Act
+	Log().Debug("discarded message")
+	SetLogLevel(slog.LevelDebug)
+	Log().Debug("debug message")
+
+	// Assert
+	assert.Equal(t, slog.LevelDebug, LogLevel())
+	assert.NotContains(t, buf.String(), "discarded message")
+	assert.Contains(t, buf.String(), "debug message")
+}
+
+// TestLogger_Bridged checks that the lines of the printf logger are written as info records through the structured logger
+func TestLogger_Bridged(t *testing.T) {
+	// Arrange
+	buf := setupTestLogger(t)
+
+	// Act
+	Logger().Printf("test message %d", 1)
+
+	// Assert
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "test message 1", record["msg"])
+}
+
+// TestSetupLogger_UnsupportedFormat checks that an error is returned when an unsupported format is provided
+func TestSetupLogger_UnsupportedFormat(t *testing.T) {
+	// Act
+	err := SetupLogger(os.Stdout, "xml")
+
+	// Assert
+	assert.Equal(t, "unsupported log format xml", err.Error())
+}
+
+// TestNewRequestID checks that unique UUID request IDs are generated
+func TestNewRequestID(t *testing.T) {
+	// Act
+	id1 := NewRequestID()
+	id2 := NewRequestID()
+
+	// Assert
+	_, err := uuid.Parse(id1)
+	assert.Nil(t, err)
+	assert.NotEqual(t, id1, id2)
+}
+
+// TestLog_TraceIDs checks that the trace and span IDs of the span held by the context of the records are added once to the log lines
+func TestLog_TraceIDs(t *testing.T) {
+	// Arrange
+	buf := setupTestLogger(t)
+	NewTracerProvider("test-service")
+	ctx, span := Tracer().Start(context.Background(), "test-span")
+	defer span.End()
+
+	// Act
+	Log().InfoContext(ctx, "span message")
+	RequestLogger(ctx, "test-request-id", "").InfoContext(ctx, "request message")
+	Log().Info("message without context")
+
+	// Assert
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, 1, strings.Count(lines[1], "trace_id"))
+	decoder := json.NewDecoder(buf)
+	for _, expectedTraceID := range []interface{}{span.SpanContext().TraceID().String(), span.SpanContext().TraceID().String(), nil} {
+		var record map[string]interface{}
+		assert.Nil(t, decoder.Decode(&record))
+		assert.Equal(t, expectedTraceID, record["trace_id"])
+	}
+}
+
+// TestLog_ReplacedTraceIDs checks that enriching a logger with the IDs of a new span replaces the IDs it carried instead of duplicating them
+func TestLog_ReplacedTraceIDs(t *testing.T) {
+	// Arrange
+	buf := setupTestLogger(t)
+	logger := RequestLogger(context.Background(), "test-request-id", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	logger.With(slog.String("trace_id", "new-trace-id"), slog.String("span_id", "new-span-id"), slog.String("key", "value")).Info("message")
+
+	// Assert
+	assert.Equal(t, 1, strings.Count(buf.String(), "trace_id"))
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "new-trace-id", record["trace_id"])
+	assert.Equal(t, "new-span-id", record["span_id"])
+	assert.Equal(t, "value", record["key"])
+	assert.Equal(t, "test-request-id", record["request_id"])
+}
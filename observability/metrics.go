@@ -0,0 +1,91 @@
+package observability
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// DefaultDurationBuckets are the buckets in seconds of the latency histograms
+	DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	// DefaultSizeBuckets are the buckets in bytes of the response size histograms
+	DefaultSizeBuckets = prometheus.ExponentialBuckets(100, 10, 7)
+)
+
+// Metrics holds the RED metrics of the HTTP and gRPC servers in its own Prometheus registry,
+// which also collects the Go runtime and process metrics
+type Metrics struct {
+	HTTPRequests     *prometheus.CounterVec
+	HTTPDuration     *prometheus.HistogramVec
+	HTTPInFlight     *prometheus.GaugeVec
+	HTTPResponseSize *prometheus.HistogramVec
+	GRPCRequests     *prometheus.CounterVec
+	GRPCDuration     *prometheus.HistogramVec
+	GRPCInFlight     *prometheus.GaugeVec
+	GRPCResponseSize *prometheus.HistogramVec
+	GRPCMsgsReceived *prometheus.CounterVec
+	GRPCMsgsSent     *prometheus.CounterVec
+	registry         *prometheus.Registry
+}
+
+// NewMetrics creates the metrics with the given namespace as name prefix, which is omitted when empty
+func NewMetrics(namespace string) *Metrics {
+	httpLabels := []string{"method", "route", "status"}
+	grpcLabels := []string{"grpc_type", "grpc_service", "grpc_method"}
+	grpcCodeLabels := append(grpcLabels, "grpc_code")
+
+	m := &Metrics{
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "http_requests_total", Help: "Total number of HTTP requests handled.",
+		}, httpLabels),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "http_request_duration_seconds", Help: "Latency of the HTTP requests.", Buckets: DefaultDurationBuckets,
+		}, httpLabels),
+		HTTPInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "http_requests_in_flight", Help: "Number of HTTP requests being handled.",
+		}, []string{"method"}),
+		HTTPResponseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "http_response_size_bytes", Help: "Size of the HTTP response bodies.", Buckets: DefaultSizeBuckets,
+		}, httpLabels),
+		GRPCRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "grpc_server_handled_total", Help: "Total number of gRPC calls handled.",
+		}, grpcCodeLabels),
+		GRPCDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "grpc_server_handling_seconds", Help: "Latency of the gRPC calls.", Buckets: DefaultDurationBuckets,
+		}, grpcCodeLabels),
+		GRPCInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "grpc_server_in_flight", Help: "Number of gRPC calls being handled.",
+		}, grpcLabels),
+		GRPCResponseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "grpc_server_response_size_bytes", Help: "Size of the gRPC responses, summing all the messages sent for streams.", Buckets: DefaultSizeBuckets,
+		}, grpcCodeLabels),
+		GRPCMsgsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "grpc_server_msg_received_total", Help: "Total number of gRPC messages received.",
+		}, grpcLabels),
+		GRPCMsgsSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "grpc_server_msg_sent_total", Help: "Total number of gRPC messages sent.",
+		}, grpcLabels),
+		registry: prometheus.NewRegistry(),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequests, m.HTTPDuration, m.HTTPInFlight, m.HTTPResponseSize,
+		m.GRPCRequests, m.GRPCDuration, m.GRPCInFlight, m.GRPCResponseSize, m.GRPCMsgsReceived, m.GRPCMsgsSent,
+	)
+	return m
+}
+
+// Registry returns the Prometheus registry of the metrics, where the application can register its own collectors
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler returns the handler exposing the metrics in the Prometheus text format, to be served on /metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,48 @@
+package observability
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetrics_Handler checks that the handler exposes the recorded, the runtime and the custom metrics in the Prometheus text format
+func TestMetrics_Handler(t *testing.T) {
+	// Arrange
+	metrics := NewMetrics("test")
+	metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/users/{id}", "200").Add(3)
+	custom := prometheus.NewCounter(prometheus.CounterOpts{Name: "custom_total", Help: "Custom counter."})
+	metrics.Registry().MustRegister(custom)
+	custom.Inc()
+	rr := httptest.NewRecorder()
+
+	// Act
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	body, _ := io.ReadAll(rr.Body)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, string(body), `test_http_requests_total{method="GET",route="/users/{id}",status="200"} 3`)
+	assert.Contains(t, string(body), "custom_total 1")
+	assert.Contains(t, string(body), "go_goroutines")
+}
+
+// TestNewMetrics_Independent checks that several metrics instances can be created without registration conflicts
+func TestNewMetrics_Independent(t *testing.T) {
+	// Act
+	first := NewMetrics("")
+	second := NewMetrics("")
+	first.GRPCRequests.WithLabelValues("unary", "TestService", "TestMethod", "OK").Inc()
+
+	// Assert
+	families, err := second.Registry().Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		assert.NotEqual(t, "grpc_server_handled_total", family.GetName())
+	}
+}
@@ -1,7 +1,6 @@
 package observability
 
 import (
-	"log"
 	"os"
 	"time"
 
@@ -9,7 +8,7 @@ import (
 	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
-// SetupNewRelic configures the application and configures the singleton logger to forward logs to New Relic
+// SetupNewRelic configures the application and configures the singleton loggers to forward logs to New Relic
 func SetupNewRelic(appName, newrelicKey string) (*newrelic.Application, error) {
 	app, err := newrelic.NewApplication(
 		newrelic.ConfigAppName(appName),
@@ -24,6 +23,8 @@ func SetupNewRelic(appName, newrelicKey string) (*newrelic.Application, error) {
 	}
 
 	writer := logWriter.New(os.Stdout, app)
-	logger = log.New(&writer, "", log.Default().Flags())
+	m.Lock()
+	defer m.Unlock()
+	setOutput(&writer)
 	return app, nil
 }
@@ -0,0 +1,323 @@
+package observability
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const defaultRedactionMask = "[REDACTED]"
+
+var (
+	// CardNumberPattern matches payment card numbers of 13 to 19 digits, optionally separated by spaces or dashes
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	// EmailPattern matches email addresses
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// Redactor masks the sensitive data of the logged requests and responses. A nil Redactor leaves the data untouched
+type Redactor struct {
+	// Fields are the JSON or protobuf fields to mask, either as dotted paths from the root such as user.password or as names matched at any depth
+	Fields []string
+	// Headers are the names of the HTTP headers to mask, case-insensitive
+	Headers []string
+	// Patterns are the regular expressions whose matches are masked in any logged string
+	Patterns []*regexp.Regexp
+	// Mask is the replacement of the redacted values
+	Mask string
+
+	fieldsText atomic.Pointer[fieldsTextPattern]
+}
+
+// fieldsTextPattern is the compiled pattern matching the values of the Fields it was built from in texts that are not valid JSON
+type fieldsTextPattern struct {
+	fields  []string
+	pattern *regexp.Regexp
+}
+
+// NewRedactor creates a new Redactor masking the given fields
+func NewRedactor(fields ...string) *Redactor {
+	r := &Redactor{
+		Fields: fields,
+		Mask:   defaultRedactionMask,
+	}
+	r.fieldsTextPattern()
+	return r
+}
+
+// DefaultRedactor returns a Redactor masking the usual credential fields and headers
+func DefaultRedactor() *Redactor {
+	r := NewRedactor("password", "secret", "token", "access_token", "refresh_token", "client_secret", "api_key")
+	r.Headers = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-API-Key"}
+	return r
+}
+
+// RedactForm masks the fields and the matches of the patterns in the URL-encoded form, keeping the order of its pairs
+// The names are matched as field paths, reading bracketed names such as user[password] as user.password
+func (r *Redactor) RedactForm(data []byte) string {
+	if r == nil || len(data) == 0 {
+		return string(data)
+	}
+
+	pairs := strings.Split(string(data), "&")
+	for i, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		path := strings.ReplaceAll(strings.ReplaceAll(name, "[", "."), "]", "")
+		if r.matchesField(path, path[strings.LastIndex(path, ".")+1:]) {
+			pairs[i] = key + "=" + r.Mask
+			continue
+		}
+
+		unescaped, err := url.QueryUnescape(value)
+		if err != nil {
+			pairs[i] = key + "=" + r.RedactString(value)
+			continue
+		}
+		if redacted := r.RedactString(unescaped); redacted != unescaped {
+			pairs[i] = key + "=" + strings.ReplaceAll(url.QueryEscape(redacted), url.QueryEscape(r.Mask), r.Mask)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// RedactString masks the matches of the patterns in the string
+func (r *Redactor) RedactString(s string) string {
+	if r == nil {
+		return s
+	}
+
+	for _, pattern := range r.Patterns {
+		s = pattern.ReplaceAllString(s, r.Mask)
+	}
+	return s
+}
+
+// RedactJSON masks the fields and the matches of the patterns in the JSON document. When it is not valid JSON, such as a truncated document,
+// the values following the names of the fields are masked textually before applying the patterns
+func (r *Redactor) RedactJSON(data []byte) string {
+	if r == nil || len(data) == 0 {
+		return string(data)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return r.RedactString(r.redactFieldsText(string(data)))
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.redactDocument(document, "")); err != nil {
+		return r.RedactString(r.redactFieldsText(string(data)))
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// RedactHeaders returns a copy of the headers with the values of the configured ones masked
+func (r *Redactor) RedactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	if r == nil {
+		return redacted
+	}
+
+	for name, values := range redacted {
+		for i := range values {
+			if r.matchesHeader(name) {
+				values[i] = r.Mask
+			} else {
+				values[i] = r.RedactString(values[i])
+			}
+		}
+	}
+	return redacted
+}
+
+// RedactValue returns a copy of the value with the sensitive data masked. Protobuf messages are cloned masking the configured fields
+// and the ones annotated with the debug_redact option, strings and byte slices are redacted as text and JSON, and any other value
+// is converted to its generic JSON representation before masking its fields
+func (r *Redactor) RedactValue(value interface{}) interface{} {
+	if r == nil || value == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case proto.Message:
+		clone := proto.Clone(v)
+		r.redactMessage(clone.ProtoReflect(), "")
+		return clone
+	case string:
+		return r.RedactString(v)
+	case []byte:
+		return r.RedactJSON(v)
+	case error:
+		return r.RedactString(v.Error())
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return value
+	}
+	return r.redactDocument(document, "")
+}
+
+func (r *Redactor) redactDocument(value interface{}, path string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			fieldPath := joinPath(path, key)
+			if r.matchesField(fieldPath, key) {
+				v[key] = r.Mask
+			} else {
+				v[key] = r.redactDocument(field, fieldPath)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactDocument(item, path)
+		}
+	case string:
+		return r.RedactString(v)
+	}
+	return value
+}
+
+func (r *Redactor) redactMessage(message protoreflect.Message, path string) {
+	var fields []protoreflect.FieldDescriptor
+	message.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	for _, fd := range fields {
+		fieldPath := joinPath(path, string(fd.Name()))
+		if r.matchesField(fieldPath, string(fd.Name())) || r.matchesField(joinPath(path, fd.JSONName()), fd.JSONName()) || debugRedact(fd) {
+			r.maskField(message, fd)
+			continue
+		}
+
+		value := message.Get(fd)
+		switch {
+		case fd.IsMap():
+			entries := value.Map()
+			var keys []protoreflect.MapKey
+			entries.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+				keys = append(keys, key)
+				return true
+			})
+			for _, key := range keys {
+				switch {
+				case fd.MapValue().Kind() == protoreflect.StringKind:
+					entries.Set(key, protoreflect.ValueOfString(r.RedactString(entries.Get(key).String())))
+				case fd.MapValue().Message() != nil:
+					r.redactMessage(entries.Get(key).Message(), fieldPath)
+				}
+			}
+		case fd.IsList():
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				switch {
+				case fd.Kind() == protoreflect.StringKind:
+					list.Set(i, protoreflect.ValueOfString(r.RedactString(list.Get(i).String())))
+				case fd.Message() != nil:
+					r.redactMessage(list.Get(i).Message(), fieldPath)
+				}
+			}
+		case fd.Kind() == protoreflect.StringKind:
+			message.Set(fd, protoreflect.ValueOfString(r.RedactString(value.String())))
+		case fd.Message() != nil:
+			r.redactMessage(value.Message(), fieldPath)
+		}
+	}
+}
+
+// redactFieldsText masks the values following the names of the fields in a text that is not valid JSON, matching dotted paths by their last name
+func (r *Redactor) redactFieldsText(s string) string {
+	pattern := r.fieldsTextPattern()
+	if pattern == nil {
+		return s
+	}
+	mask, _ := json.Marshal(r.Mask)
+	return pattern.ReplaceAllString(s, "${1}"+strings.ReplaceAll(string(mask), "$", "$$"))
+}
+
+// fieldsTextPattern returns the pattern used by redactFieldsText, which is only compiled again when the Fields change
+func (r *Redactor) fieldsTextPattern() *regexp.Regexp {
+	if len(r.Fields) == 0 {
+		return nil
+	}
+	if cached := r.fieldsText.Load(); cached != nil && slices.Equal(cached.fields, r.Fields) {
+		return cached.pattern
+	}
+
+	names := make([]string, 0, len(r.Fields))
+	for _, field := range r.Fields {
+		names = append(names, regexp.QuoteMeta(field[strings.LastIndex(field, ".")+1:]))
+	}
+	pattern := regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	r.fieldsText.Store(&fieldsTextPattern{fields: slices.Clone(r.Fields), pattern: pattern})
+	return pattern
+}
+
+func (r *Redactor) maskField(message protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.IsList() || fd.IsMap():
+		message.Clear(fd)
+	case fd.Kind() == protoreflect.StringKind:
+		message.Set(fd, protoreflect.ValueOfString(r.Mask))
+	case fd.Kind() == protoreflect.BytesKind:
+		message.Set(fd, protoreflect.ValueOfBytes([]byte(r.Mask)))
+	default:
+		message.Clear(fd)
+	}
+}
+
+func (r *Redactor) matchesField(path, name string) bool {
+	for _, field := range r.Fields {
+		if field == path || (!strings.Contains(field, ".") && strings.EqualFold(field, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) matchesHeader(name string) bool {
+	for _, header := range r.Headers {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func debugRedact(fd protoreflect.FieldDescriptor) bool {
+	options, ok := fd.Options().(*descriptorpb.FieldOptions)
+	return ok && options.GetDebugRedact()
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
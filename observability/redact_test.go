@@ -0,0 +1,203 @@
+package observability
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newTestMessage builds a dynamic User message with a name, a password, notes annotated with debug_redact, a nested card and a list of emails
+func newTestMessage(t *testing.T) *dynamicpb.Message {
+	t.Helper()
+
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), JsonName: proto.String(name), Number: proto.Int32(number), Type: kind.Enum(), Label: label.Enum()}
+	}
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	notes := field("notes", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional)
+	notes.Options = &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)}
+	card := field("card", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, optional)
+	card.TypeName = proto.String(".test.Card")
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("User"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				field("password", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				notes,
+				card,
+				field("emails", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, repeated),
+			}},
+			{Name: proto.String("Card"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("number", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional),
+				field("cvv", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional),
+			}},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error building the message descriptor: %s", err)
+	}
+
+	user := dynamicpb.NewMessage(file.Messages().ByName("User"))
+	fields := user.Descriptor().Fields()
+	user.Set(fields.ByName("name"), protoreflect.ValueOfString("test-user"))
+	user.Set(fields.ByName("password"), protoreflect.ValueOfString("test-password"))
+	user.Set(fields.ByName("notes"), protoreflect.ValueOfString("test-notes"))
+	cardMessage := user.Mutable(fields.ByName("card")).Message()
+	cardMessage.Set(cardMessage.Descriptor().Fields().ByName("number"), protoreflect.ValueOfString("4111 1111 1111 1111"))
+	cardMessage.Set(cardMessage.Descriptor().Fields().ByName("cvv"), protoreflect.ValueOfInt32(123))
+	user.Mutable(fields.ByName("emails")).List().Append(protoreflect.ValueOfString("contact: user@test.com"))
+	return user
+}
+
+// TestRedactor_RedactJSON checks that the configured fields and the matches of the patterns are masked in JSON documents
+func TestRedactor_RedactJSON(t *testing.T) {
+	redactor := NewRedactor("password", "user.card.cvv")
+	redactor.Patterns = []*regexp.Regexp{CardNumberPattern, EmailPattern}
+
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"Field at any depth", `{"password":"secret","nested":[{"Password":"secret","id":1}]}`, `{"nested":[{"Password":"[REDACTED]","id":1}],"password":"[REDACTED]"}`},
+		{"Field path", `{"user":{"card":{"cvv":123,"number":"4111-1111-1111-1111"}},"cvv":456}`, `{"cvv":456,"user":{"card":{"cvv":"[REDACTED]","number":"[REDACTED]"}}}`},
+		{"Pattern in value", `{"message":"contact user@test.com <now>"}`, `{"message":"contact [REDACTED] <now>"}`},
+		{"Truncated JSON", `{"user":{"card":{"cvv":123},"Password":"sec`, `{"user":{"card":{"cvv":"[REDACTED]"},"Password":"[REDACTED]"`},
+		{"Not JSON", `password=secret&email=user@test.com`, `password=secret&email=[REDACTED]`},
+		{"Empty body", ``, ``},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			redacted := redactor.RedactJSON([]byte(tt.body))
+
+			// Assert
+			assert.Equal(t, tt.expected, redacted)
+		})
+	}
+}
+
+// TestRedactor_RedactForm checks that the configured fields and the matches of the patterns are masked in URL-encoded forms
+func TestRedactor_RedactForm(t *testing.T) {
+	redactor := NewRedactor("password", "user.card.cvv")
+	redactor.Patterns = []*regexp.Regexp{EmailPattern}
+
+	cases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"Field", `username=test-user&password=secret`, `username=test-user&password=[REDACTED]`},
+		{"Field case-insensitive", `Password=secret&remember=on`, `Password=[REDACTED]&remember=on`},
+		{"Bracketed field path", `user%5Bcard%5D%5Bcvv%5D=123&user[name]=test-user`, `user%5Bcard%5D%5Bcvv%5D=[REDACTED]&user[name]=test-user`},
+		{"Pattern in value", `email=user%40test.com&note=contact+user@test.com+now`, `email=[REDACTED]&note=contact+[REDACTED]+now`},
+		{"Field without value", `password&username=test-user`, `password=[REDACTED]&username=test-user`},
+		{"Truncated form", `username=test-user&passw`, `username=test-user&passw`},
+		{"Empty body", ``, ``},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			redacted := redactor.RedactForm([]byte(tt.body))
+
+			// Assert
+			assert.Equal(t, tt.expected, redacted)
+		})
+	}
+}
+
+// TestRedactor_FieldsTextPattern checks that the pattern used for texts that are not valid JSON is compiled once and again only when the fields change
+func TestRedactor_FieldsTextPattern(t *testing.T) {
+	// Arrange
+	redactor := NewRedactor("password")
+	first := redactor.fieldsTextPattern()
+
+	// Act
+	second := redactor.fieldsTextPattern()
+	redactor.Fields = []string{"password", "pin"}
+	third := redactor.fieldsTextPattern()
+
+	// Assert
+	assert.Same(t, first, second)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, `{"pin":"[REDACTED]"`, redactor.RedactJSON([]byte(`{"pin":"1234"`)))
+}
+
+// TestRedactor_RedactHeaders checks that the configured headers are masked case-insensitively without modifying the original ones
+func TestRedactor_RedactHeaders(t *testing.T) {
+	// Arrange
+	headers := http.Header{"Authorization": {"Bearer test-token"}, "X-Api-Key": {"test-key"}, "Accept": {"application/json"}}
+
+	// Act
+	redacted := DefaultRedactor().RedactHeaders(headers)
+
+	// Assert
+	assert.Equal(t, http.Header{"Authorization": {"[REDACTED]"}, "X-Api-Key": {"[REDACTED]"}, "Accept": {"application/json"}}, redacted)
+	assert.Equal(t, "Bearer test-token", headers.Get("Authorization"))
+}
+
+// TestRedactor_RedactValue_Proto checks that the configured fields, the debug_redact fields and the matches of the patterns are masked in a copy of protobuf messages
+func TestRedactor_RedactValue_Proto(t *testing.T) {
+	// Arrange
+	message := newTestMessage(t)
+	redactor := NewRedactor("password", "card.cvv")
+	redactor.Patterns = []*regexp.Regexp{CardNumberPattern, EmailPattern}
+
+	// Act
+	redacted := redactor.RedactValue(message).(*dynamicpb.Message)
+
+	// Assert
+	fields := redacted.Descriptor().Fields()
+	card := redacted.Get(fields.ByName("card")).Message()
+	assert.Equal(t, "test-user", redacted.Get(fields.ByName("name")).String())
+	assert.Equal(t, "[REDACTED]", redacted.Get(fields.ByName("password")).String())
+	assert.Equal(t, "[REDACTED]", redacted.Get(fields.ByName("notes")).String())
+	assert.Equal(t, "[REDACTED]", card.Get(card.Descriptor().Fields().ByName("number")).String())
+	assert.False(t, card.Has(card.Descriptor().Fields().ByName("cvv")))
+	assert.Equal(t, "contact: [REDACTED]", redacted.Get(fields.ByName("emails")).List().Get(0).String())
+	assert.Equal(t, "test-password", message.Get(fields.ByName("password")).String())
+}
+
+// TestRedactor_RedactValue checks that the non-protobuf values are redacted through their JSON representation
+func TestRedactor_RedactValue(t *testing.T) {
+	// Arrange
+	redactor := DefaultRedactor()
+	value := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{"test-user", "test-password"}
+
+	// Act
+	redacted := redactor.RedactValue(value)
+
+	// Assert
+	assert.Equal(t, map[string]interface{}{"username": "test-user", "password": "[REDACTED]"}, redacted)
+	assert.Equal(t, "test-request", redactor.RedactValue("test-request"))
+	assert.Nil(t, redactor.RedactValue(nil))
+}
+
+// TestRedactor_Nil checks that a nil Redactor leaves the data untouched
+func TestRedactor_Nil(t *testing.T) {
+	// Arrange
+	var redactor *Redactor
+
+	// Act
+	redacted := redactor.RedactJSON([]byte(`{"password":"secret"}`))
+
+	// Assert
+	assert.Equal(t, `{"password":"secret"}`, redacted)
+	assert.Equal(t, "secret", redactor.RedactString("secret"))
+}
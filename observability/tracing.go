@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the spans created by the toolkit
+const TracerName = "github.com/sergicanet9/scv-go-tools/v4"
+
+// SetupOpenTelemetry configures the global tracer provider to export the spans of the service in batches to the OTLP HTTP endpoint,
+// such as http://localhost:4318, or to the one set in the OTEL_EXPORTER_OTLP_ENDPOINT environment variable when empty,
+// and the W3C trace context and baggage propagators. The provider must be shut down to flush the pending spans
+func SetupOpenTelemetry(ctx context.Context, serviceName, endpoint string) (*sdktrace.TracerProvider, error) {
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid OTLP endpoint %s", endpoint)
+		}
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	return NewTracerProvider(serviceName, sdktrace.WithBatcher(exporter)), nil
+}
+
+// Tracer returns the tracer of the toolkit from the global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// NewTracerProvider configures the global tracer provider with the resource of the service and the given options,
+// such as the exporter of the spans, and the W3C trace context and baggage propagators
+func NewTracerProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		res = resource.NewSchemaless(semconv.ServiceName(serviceName))
+	}
+
+	provider := sdktrace.NewTracerProvider(append(opts, sdktrace.WithResource(res))...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider
+}
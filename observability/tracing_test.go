@@ -0,0 +1,45 @@
+package observability
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSetupOpenTelemetry checks that the spans are exported to the OTLP HTTP endpoint when the provider is shut down
+func TestSetupOpenTelemetry(t *testing.T) {
+	// Arrange
+	var requests atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" {
+			requests.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	provider, err := SetupOpenTelemetry(context.Background(), "test-service", collector.URL+"/v1/traces")
+	assert.Nil(t, err)
+
+	// Act
+	_, span := Tracer().Start(context.Background(), "test-span")
+	span.End()
+	err = provider.Shutdown(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+// TestSetupOpenTelemetry_InvalidEndpoint checks that an error is returned when the endpoint is not a valid URL
+func TestSetupOpenTelemetry_InvalidEndpoint(t *testing.T) {
+	// Act
+	_, err := SetupOpenTelemetry(context.Background(), "test-service", "://invalid")
+
+	// Assert
+	assert.Equal(t, "invalid OTLP endpoint ://invalid", err.Error())
+}
@@ -0,0 +1,209 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule returns the next activation time after the given time
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// cronSchedule is a Schedule parsed from a standard 5-field cron expression, each field stored as a bit set
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	location                      *time.Location
+}
+
+// everySchedule is a Schedule with a fixed interval
+type everySchedule struct {
+	interval time.Duration
+}
+
+type field struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{min: 0, max: 59}
+	hourField   = field{min: 0, max: 23}
+	domField    = field{min: 1, max: 31}
+	monthField  = field{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearch is the limit when looking for the next activation time of expressions that never match, such as 30 February
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// ParseCron parses a standard cron expression with the fields minute, hour, day of month, month and day of week,
+// supporting *, lists, ranges, steps and month and weekday names, as well as the descriptors @yearly, @annually,
+// @monthly, @weekly, @daily, @midnight, @hourly and @every <duration>
+// The activation times are computed in the given location, or in the local time when nil
+func ParseCron(spec string, location *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if location == nil {
+		location = time.Local
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %s: %w", spec, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid cron expression %s: interval must be positive", spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if expression, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = expression
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %s: expected 5 fields but got %d", spec, len(fields))
+	}
+
+	schedule := &cronSchedule{location: location}
+	targets := []*uint64{&schedule.minute, &schedule.hour, &schedule.dom, &schedule.month, &schedule.dow}
+	for i, f := range []field{minuteField, hourField, domField, monthField, dowField} {
+		set, err := f.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %s: %w", spec, err)
+		}
+		*targets[i] = set
+	}
+
+	// 7 is accepted as an alias of Sunday
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow = schedule.dow&^(1<<7) | 1
+	}
+	return schedule, nil
+}
+
+func (f field) parse(expr string) (uint64, error) {
+	var result uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeExpr = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s", part)
+			}
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if start, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+		default:
+			value, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			start = value
+			if step == 1 {
+				end = value
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range in %s", part)
+		}
+
+		for v := start; v <= end; v += step {
+			result |= 1 << uint(v)
+		}
+	}
+	return result, nil
+}
+
+func (f field) value(expr string) (int, error) {
+	if v, ok := f.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %s out of range [%d-%d]", expr, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the next activation time after the given time, or the zero time when there is none
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	original := t.Location()
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t.In(original)
+	}
+	return time.Time{}
+}
+
+// matchesDay follows the cron convention: when both day of month and day of week are restricted, any of them matches
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	domRestricted := bits.OnesCount64(s.dom) < 31
+	dowRestricted := bits.OnesCount64(s.dow) < 7
+
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+	if domRestricted && dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// Next returns the given time plus the interval
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseCron_Next checks that the parsed schedules return the expected next activation time
+func TestParseCron_Next(t *testing.T) {
+	from := time.Date(2024, time.January, 15, 10, 30, 45, 0, time.UTC) // Monday
+
+	cases := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{"Every minute", "* * * * *", time.Date(2024, time.January, 15, 10, 31, 0, 0, time.UTC)},
+		{"Fixed minute", "45 * * * *", time.Date(2024, time.January, 15, 10, 45, 0, 0, time.UTC)},
+		{"Minute step", "*/20 * * * *", time.Date(2024, time.January, 15, 10, 40, 0, 0, time.UTC)},
+		{"Hour range", "0 12-14 * * *", time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)},
+		{"List", "0 8,9 * * *", time.Date(2024, time.January, 16, 8, 0, 0, 0, time.UTC)},
+		{"Range with step", "0 0-12/6 * * *", time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)},
+		{"Day of month", "0 0 1 * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"Month name", "0 0 1 mar *", time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"Weekday name", "0 9 * * fri", time.Date(2024, time.January, 19, 9, 0, 0, 0, time.UTC)},
+		{"Sunday as 7", "0 0 * * 7", time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"Day of month or weekday", "0 0 20 * mon", time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC)},
+		{"Leap day", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"Hourly", "@hourly", time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{"Daily", "@daily", time.Date(2024, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"Weekly", "@weekly", time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC)},
+		{"Monthly", "@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"Yearly", "@yearly", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"Every", "@every 90s", from.Add(90 * time.Second)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := ParseCron(tc.spec, time.UTC)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, schedule.Next(from))
+		})
+	}
+}
+
+// TestParseCron_Invalid checks that ParseCron returns an error for invalid expressions
+func TestParseCron_Invalid(t *testing.T) {
+	cases := []struct {
+		name        string
+		spec        string
+		expectedErr string
+	}{
+		{"Wrong number of fields", "* * *", "invalid cron expression * * *: expected 5 fields but got 3"},
+		{"Out of range", "60 * * * *", "invalid cron expression 60 * * * *: value 60 out of range [0-59]"},
+		{"Invalid name", "0 0 * foo *", "invalid cron expression 0 0 * foo *: value foo out of range [1-12]"},
+		{"Invalid step", "*/0 * * * *", "invalid cron expression */0 * * * *: invalid step in */0"},
+		{"Inverted range", "0 10-5 * * *", "invalid cron expression 0 10-5 * * *: invalid range in 10-5"},
+		{"Invalid range bound", "0 1-x * * *", "invalid cron expression 0 1-x * * *: value x out of range [0-23]"},
+		{"Invalid every", "@every 10", "invalid cron expression @every 10: time: missing unit in duration \"10\""},
+		{"Negative every", "@every -1s", "invalid cron expression @every -1s: interval must be positive"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseCron(tc.spec, time.UTC)
+
+			assert.Equal(t, tc.expectedErr, err.Error())
+		})
+	}
+}
+
+// TestParseCron_NeverMatches checks that Next returns the zero time when the expression never matches
+func TestParseCron_NeverMatches(t *testing.T) {
+	// Arrange
+	schedule, _ := ParseCron("0 0 30 2 *", time.UTC)
+
+	// Act
+	next := schedule.Next(time.Now())
+
+	// Assert
+	assert.True(t, next.IsZero())
+}
+
+// TestParseCron_Location checks that the activation times are computed in the given location
+func TestParseCron_Location(t *testing.T) {
+	// Arrange
+	location := time.FixedZone("UTC+2", 2*60*60)
+	schedule, _ := ParseCron("0 9 * * *", location)
+	from := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	next := schedule.Next(from)
+
+	// Assert
+	assert.Equal(t, time.Date(2024, time.January, 15, 7, 0, 0, 0, time.UTC), next)
+}
@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/sergicanet9/scv-go-tools/v4/lock"
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+)
+
+const (
+	defaultLeaderKey     = "scheduler"
+	defaultRenewInterval = 10 * time.Second
+)
+
+// Task is a periodic unit of work run by the Scheduler
+type Task func(ctx context.Context) error
+
+// Scheduler runs the registered tasks following their cron schedules
+// When a Locker is configured, the replicas elect a leader through it and only the leader runs the tasks
+type Scheduler struct {
+	// LeaderKey is the name of the lock used for the leader election
+	LeaderKey string
+	// RenewInterval is the interval used to renew the leadership and to retry acquiring it
+	RenewInterval time.Duration
+	// Location is the time zone in which the cron expressions are evaluated
+	Location *time.Location
+
+	locker  lock.Locker
+	app     *newrelic.Application
+	entries []entry
+}
+
+type entry struct {
+	name     string
+	schedule Schedule
+	task     Task
+}
+
+// NewScheduler creates a new scheduler
+// The locker ensures a single execution across replicas and the app records every run as a New Relic background transaction,
+// both being optional when nil
+func NewScheduler(locker lock.Locker, app *newrelic.Application) *Scheduler {
+	return &Scheduler{
+		LeaderKey:     defaultLeaderKey,
+		RenewInterval: defaultRenewInterval,
+		Location:      time.Local,
+		locker:        locker,
+		app:           app,
+	}
+}
+
+// Register adds a task to be run following the given cron expression, see ParseCron for the supported syntax
+func (s *Scheduler) Register(name, spec string, task Task) error {
+	schedule, err := ParseCron(spec, s.Location)
+	if err != nil {
+		return err
+	}
+	s.entries = append(s.entries, entry{name: name, schedule: schedule, task: task})
+	return nil
+}
+
+// Run runs the registered tasks until the context is done, waiting for the running tasks before returning
+// With a Locker, the tasks are only run while holding the leadership, which is retried periodically when not held or lost
+// An error is returned when no task is registered or when the RenewInterval would let the leadership expire between renewals
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.entries) == 0 {
+		return errors.New("no tasks registered")
+	}
+	if s.locker == nil {
+		s.run(ctx)
+		return ctx.Err()
+	}
+	if err := s.validateRenewInterval(); err != nil {
+		return err
+	}
+
+	for {
+		lease, acquired, err := s.locker.TryLock(ctx, s.LeaderKey)
+		if err != nil && ctx.Err() == nil {
+			observability.Logger().Printf("scheduler failed to acquire leadership: %v", err)
+		}
+
+		if acquired {
+			observability.Logger().Printf("scheduler acquired leadership %s", s.LeaderKey)
+			stopped := s.lead(ctx, lease)
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if stopped {
+				observability.Logger().Printf("scheduler stopped, no task has a next activation time")
+				return nil
+			}
+			observability.Logger().Printf("scheduler lost leadership %s", s.LeaderKey)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.RenewInterval):
+		}
+	}
+}
+
+// lead runs the tasks while the lease is held, releasing it afterwards, and reports whether all the tasks stopped by themselves
+func (s *Scheduler) lead(ctx context.Context, lease lock.Lease) bool {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lost := lock.KeepAlive(leaderCtx, lease, s.RenewInterval)
+	go func() {
+		<-lost
+		cancel()
+	}()
+
+	s.run(leaderCtx)
+	stopped := leaderCtx.Err() == nil
+	cancel()
+
+	unlockCtx, cancelUnlock := context.WithTimeout(context.WithoutCancel(ctx), s.RenewInterval)
+	defer cancelUnlock()
+	if err := lease.Unlock(unlockCtx); err != nil && !errors.Is(err, lock.ErrLeaseLost) {
+		observability.Logger().Printf("scheduler failed to release leadership %s: %v", s.LeaderKey, err)
+	}
+	return stopped
+}
+
+func (s *Scheduler) validateRenewInterval() error {
+	if s.RenewInterval <= 0 {
+		return fmt.Errorf("renew interval must be positive, got %s", s.RenewInterval)
+	}
+	if locker, ok := s.locker.(lock.ExpiringLocker); ok && locker.TTL() > 0 && s.RenewInterval >= locker.TTL() {
+		return fmt.Errorf("renew interval %s must be shorter than the lease ttl %s", s.RenewInterval, locker.TTL())
+	}
+	return nil
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range s.entries {
+		wg.Add(1)
+		go func(e entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			observability.Logger().Printf("scheduled task %s has no next activation time, stopping it", e.name)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.execute(ctx, e)
+		}
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, e entry) {
+	if s.app != nil {
+		txn := s.app.StartTransaction(e.name)
+		defer txn.End()
+		ctx = newrelic.NewContext(ctx, txn)
+	}
+
+	start := time.Now()
+	err := runTask(ctx, e)
+	latency := time.Since(start)
+
+	if err != nil {
+		if txn := newrelic.FromContext(ctx); txn != nil {
+			txn.NoticeError(err)
+		}
+		observability.Logger().Printf("Scheduled task: %s - Status: failed - Latency: %s - Error: %v", e.name, latency, err)
+		return
+	}
+	observability.Logger().Printf("Scheduled task: %s - Status: done - Latency: %s", e.name, latency)
+}
+
+func runTask(ctx context.Context, e entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered from panic during scheduled task %s, Panic: %v", e.name, r)
+		}
+	}()
+	return e.task(ctx)
+}
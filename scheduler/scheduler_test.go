@@ -0,0 +1,229 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/sergicanet9/scv-go-tools/v4/lock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegister_InvalidSpec checks that Register returns an error when the cron expression is not valid
+func TestRegister_InvalidSpec(t *testing.T) {
+	// Arrange
+	scheduler := NewScheduler(nil, nil)
+
+	// Act
+	err := scheduler.Register("test", "invalid", func(ctx context.Context) error { return nil })
+
+	// Assert
+	assert.NotEmpty(t, err)
+}
+
+// TestRun_WithoutLocker checks that the tasks are run periodically until the context is done
+func TestRun_WithoutLocker(t *testing.T) {
+	// Arrange
+	var runs int32
+	scheduler := NewScheduler(nil, nil)
+	scheduler.Register("test", "@every 5ms", func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	done := make(chan error)
+	go func() { done <- scheduler.Run(ctx) }()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, time.Second, time.Millisecond)
+	cancel()
+
+	// Assert
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+// TestRun_LeaderElection checks that only the replica holding the leadership runs the tasks
+func TestRun_LeaderElection(t *testing.T) {
+	// Arrange
+	locker := lock.NewMemoryLocker(time.Second)
+	var leaderRuns, followerRuns int32
+
+	leader := NewScheduler(locker, nil)
+	leader.RenewInterval = 5 * time.Millisecond
+	leader.Register("test", "@every 5ms", func(ctx context.Context) error {
+		atomic.AddInt32(&leaderRuns, 1)
+		return nil
+	})
+
+	follower := NewScheduler(locker, nil)
+	follower.RenewInterval = 5 * time.Millisecond
+	follower.Register("test", "@every 5ms", func(ctx context.Context) error {
+		atomic.AddInt32(&followerRuns, 1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	leaderDone := make(chan error)
+	go func() { leaderDone <- leader.Run(ctx) }()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&leaderRuns) >= 1 }, time.Second, time.Millisecond)
+	go follower.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	// Assert
+	assert.Equal(t, int32(0), atomic.LoadInt32(&followerRuns))
+	cancel()
+	assert.Equal(t, context.Canceled, <-leaderDone)
+}
+
+// TestRun_Failover checks that another replica takes over the leadership when the leader stops
+func TestRun_Failover(t *testing.T) {
+	// Arrange
+	locker := lock.NewMemoryLocker(time.Second)
+	var followerRuns int32
+
+	leader := NewScheduler(locker, nil)
+	leader.RenewInterval = 5 * time.Millisecond
+	leader.Register("test", "@every 5ms", func(ctx context.Context) error { return nil })
+
+	follower := NewScheduler(locker, nil)
+	follower.RenewInterval = 5 * time.Millisecond
+	follower.Register("test", "@every 5ms", func(ctx context.Context) error {
+		atomic.AddInt32(&followerRuns, 1)
+		return nil
+	})
+
+	leaderCtx, stopLeader := context.WithCancel(context.Background())
+	followerCtx, stopFollower := context.WithCancel(context.Background())
+	defer stopFollower()
+
+	leaderDone := make(chan error)
+	go func() { leaderDone <- leader.Run(leaderCtx) }()
+	time.Sleep(10 * time.Millisecond)
+	go follower.Run(followerCtx)
+
+	// Act
+	stopLeader()
+	<-leaderDone
+
+	// Assert
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&followerRuns) >= 1 }, time.Second, time.Millisecond)
+}
+
+// TestRun_NoTasks checks that Run returns an error right away when no task is registered
+func TestRun_NoTasks(t *testing.T) {
+	// Arrange
+	scheduler := NewScheduler(lock.NewMemoryLocker(time.Second), nil)
+
+	// Act
+	err := scheduler.Run(context.Background())
+
+	// Assert
+	assert.Equal(t, "no tasks registered", err.Error())
+}
+
+// TestRun_InvalidRenewInterval checks that Run returns an error when the leadership would expire between renewals
+func TestRun_InvalidRenewInterval(t *testing.T) {
+	cases := []struct {
+		name          string
+		renewInterval time.Duration
+		expectedErr   string
+	}{
+		{"Not shorter than the ttl", time.Second, "renew interval 1s must be shorter than the lease ttl 1s"},
+		{"Not positive", 0, "renew interval must be positive, got 0s"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			scheduler := NewScheduler(lock.NewMemoryLocker(time.Second), nil)
+			scheduler.RenewInterval = tc.renewInterval
+			scheduler.Register("test", "@every 5ms", func(ctx context.Context) error { return nil })
+
+			// Act
+			err := scheduler.Run(context.Background())
+
+			// Assert
+			assert.Equal(t, tc.expectedErr, err.Error())
+		})
+	}
+}
+
+// TestRun_ReleasesLeadership checks that the leadership is released when the tasks stop by themselves
+func TestRun_ReleasesLeadership(t *testing.T) {
+	// Arrange
+	locker := lock.NewMemoryLocker(0)
+	scheduler := NewScheduler(locker, nil)
+	scheduler.RenewInterval = 5 * time.Millisecond
+	scheduler.Register("test", "0 0 30 2 *", func(ctx context.Context) error { return nil })
+
+	// Act
+	err := scheduler.Run(context.Background())
+
+	// Assert
+	assert.Nil(t, err)
+	_, acquired, _ := locker.TryLock(context.Background(), scheduler.LeaderKey)
+	assert.True(t, acquired)
+}
+
+// TestExecute_Error checks that a failing task does not stop the scheduler
+func TestExecute_Error(t *testing.T) {
+	// Arrange
+	var runs int32
+	scheduler := NewScheduler(nil, nil)
+	scheduler.Register("test", "@every 5ms", func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("test-error")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	go scheduler.Run(ctx)
+
+	// Assert
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, time.Second, time.Millisecond)
+}
+
+// TestRunTask_Panic checks that runTask recovers from a panic in the task and returns it as an error
+func TestRunTask_Panic(t *testing.T) {
+	// Arrange
+	e := entry{name: "test", task: func(ctx context.Context) error { panic("test panic") }}
+	expectedError := "recovered from panic during scheduled task test, Panic: test panic"
+
+	// Act
+	err := runTask(context.Background(), e)
+
+	// Assert
+	assert.Equal(t, expectedError, err.Error())
+}
+
+// TestExecute_NewRelicTransaction checks that the task receives a context with a New Relic transaction when an app is configured
+func TestExecute_NewRelicTransaction(t *testing.T) {
+	// Arrange
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("test-app"),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var txn *newrelic.Transaction
+	scheduler := NewScheduler(nil, app)
+	e := entry{name: "test", task: func(ctx context.Context) error {
+		txn = newrelic.FromContext(ctx)
+		return errors.New("test-error")
+	}}
+
+	// Act
+	scheduler.execute(context.Background(), e)
+
+	// Assert
+	assert.NotNil(t, txn)
+}
@@ -0,0 +1,25 @@
+package testutils
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCertificateAuthority_IssueCertificate checks that the issued certificates are verified by the pool of the authority and contain the SANs
+func TestCertificateAuthority_IssueCertificate(t *testing.T) {
+	// Arrange
+	ca := NewCertificateAuthority(t)
+
+	// Act
+	cert := ca.IssueCertificate(t, "test-service", "localhost", "127.0.0.1", "spiffe://example.org/test-service")
+
+	// Assert
+	_, err := cert.Leaf.Verify(x509.VerifyOptions{Roots: ca.Pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.Nil(t, err)
+	assert.Equal(t, "test-service", cert.Leaf.Subject.CommonName)
+	assert.Equal(t, []string{"localhost"}, cert.Leaf.DNSNames)
+	assert.Equal(t, "127.0.0.1", cert.Leaf.IPAddresses[0].String())
+	assert.Equal(t, "spiffe://example.org/test-service", cert.Leaf.URIs[0].String())
+}
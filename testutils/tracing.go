@@ -0,0 +1,23 @@
+package testutils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sergicanet9/scv-go-tools/v4/observability"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// SetupInMemoryTracing configures the global tracer provider to record synchronously the spans of the service in the returned
+// in-memory exporter, and the W3C trace context and baggage propagators, so that tests can assert the spans without a collector.
+// The provider is shut down when the test finishes
+func SetupInMemoryTracing(t *testing.T, serviceName string) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := observability.NewTracerProvider(serviceName, sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	return exporter
+}
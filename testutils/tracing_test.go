@@ -0,0 +1,30 @@
+package testutils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// TestSetupInMemoryTracing checks that the spans are recorded with the service name and the trace context is propagated as W3C traceparent
+func TestSetupInMemoryTracing(t *testing.T) {
+	// Arrange
+	exporter := SetupInMemoryTracing(t, "test-service")
+
+	// Act
+	ctx, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	span.End()
+
+	// Assert
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "test-span", spans[0].Name)
+	assert.Contains(t, spans[0].Resource.Attributes(), semconv.ServiceName("test-service"))
+	assert.Equal(t, "00-"+spans[0].SpanContext.TraceID().String()+"-"+spans[0].SpanContext.SpanID().String()+"-01", carrier.Get("traceparent"))
+}
@@ -0,0 +1,270 @@
+package wrappers
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseWriter is an http.ResponseWriter that records the status code, the bytes written and the timing of the response
+type ResponseWriter interface {
+	http.ResponseWriter
+	// Status returns the status code of the response, which is http.StatusOK when the handler did not set it
+	// and http.StatusSwitchingProtocols when the handler took over the connection before writing any header
+	Status() int
+	// BytesWritten returns the number of bytes of the response body written so far
+	BytesWritten() int64
+	// WroteHeader reports whether the final header has been written
+	WroteHeader() bool
+	// HeaderWrittenAt returns when the final header was written, or the zero time when it has not been written yet
+	HeaderWrittenAt() time.Time
+	// Hijacked reports whether the handler took over the connection
+	Hijacked() bool
+	// Tee sets a writer that receives a copy of the response body as it is written
+	Tee(w io.Writer)
+	// Unwrap returns the underlying writer, which is used by http.ResponseController
+	Unwrap() http.ResponseWriter
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status          int
+	bytesWritten    int64
+	wroteHeader     bool
+	headerWrittenAt time.Time
+	hijacked        bool
+	tee             io.Writer
+}
+
+// NewResponseWriter wraps the writer in a ResponseWriter that also implements the optional interfaces
+// among http.Flusher, http.Hijacker, io.ReaderFrom and http.Pusher that the writer supports, and only those
+func NewResponseWriter(w http.ResponseWriter) ResponseWriter {
+	rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+	const (
+		flusherBit = 1 << iota
+		hijackerBit
+		readerFromBit
+		pusherBit
+	)
+	var bits int
+	if _, ok := w.(http.Flusher); ok {
+		bits |= flusherBit
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		bits |= hijackerBit
+	}
+	if _, ok := w.(io.ReaderFrom); ok {
+		bits |= readerFromBit
+	}
+	if _, ok := w.(http.Pusher); ok {
+		bits |= pusherBit
+	}
+
+	switch bits {
+	case flusherBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+		}{rw, rw}
+	case hijackerBit:
+		return struct {
+			ResponseWriter
+			http.Hijacker
+		}{rw, rw}
+	case flusherBit | hijackerBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, rw, rw}
+	case readerFromBit:
+		return struct {
+			ResponseWriter
+			io.ReaderFrom
+		}{rw, rw}
+	case flusherBit | readerFromBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{rw, rw, rw}
+	case hijackerBit | readerFromBit:
+		return struct {
+			ResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, rw, rw}
+	case flusherBit | hijackerBit | readerFromBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, rw, rw, rw}
+	case pusherBit:
+		return struct {
+			ResponseWriter
+			http.Pusher
+		}{rw, rw}
+	case flusherBit | pusherBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+			http.Pusher
+		}{rw, rw, rw}
+	case hijackerBit | pusherBit:
+		return struct {
+			ResponseWriter
+			http.Hijacker
+			http.Pusher
+		}{rw, rw, rw}
+	case flusherBit | hijackerBit | pusherBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rw, rw, rw, rw}
+	case readerFromBit | pusherBit:
+		return struct {
+			ResponseWriter
+			io.ReaderFrom
+			http.Pusher
+		}{rw, rw, rw}
+	case flusherBit | readerFromBit | pusherBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+			io.ReaderFrom
+			http.Pusher
+		}{rw, rw, rw, rw}
+	case hijackerBit | readerFromBit | pusherBit:
+		return struct {
+			ResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+		}{rw, rw, rw, rw}
+	case flusherBit | hijackerBit | readerFromBit | pusherBit:
+		return struct {
+			ResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+		}{rw, rw, rw, rw, rw}
+	default:
+		return struct {
+			ResponseWriter
+		}{rw}
+	}
+}
+
+// WriteHeader records the final status code and writes it to the underlying writer, the informational 1xx codes other than 101 are not recorded
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader && !rw.hijacked && (code >= http.StatusOK || code == http.StatusSwitchingProtocols) {
+		rw.status = code
+		rw.markHeaderWritten()
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the data to the underlying writer and to the tee writer, recording the bytes written
+func (rw *responseWriter) Write(data []byte) (int, error) {
+	rw.markHeaderWritten()
+	n, err := rw.ResponseWriter.Write(data)
+	rw.record(data[:n])
+	return n, err
+}
+
+// Flush sends any buffered data to the client
+func (rw *responseWriter) Flush() {
+	rw.markHeaderWritten()
+	rw.ResponseWriter.(http.Flusher).Flush()
+}
+
+// Hijack lets the handler take over the connection
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := rw.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		rw.hijacked = true
+		if !rw.wroteHeader {
+			rw.status = http.StatusSwitchingProtocols
+		}
+	}
+	return conn, buf, err
+}
+
+// ReadFrom copies the data from the reader to the underlying writer, using its io.ReaderFrom implementation unless a tee writer is set
+func (rw *responseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rw.tee != nil {
+		return io.Copy(writerOnly{rw}, src)
+	}
+
+	rw.markHeaderWritten()
+	n, err := rw.ResponseWriter.(io.ReaderFrom).ReadFrom(src)
+	rw.bytesWritten += n
+	return n, err
+}
+
+// Push initiates an HTTP/2 server push
+func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
+	return rw.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+// Status returns the status code of the response
+func (rw *responseWriter) Status() int {
+	return rw.status
+}
+
+// BytesWritten returns the number of bytes of the response body written so far
+func (rw *responseWriter) BytesWritten() int64 {
+	return rw.bytesWritten
+}
+
+// WroteHeader reports whether the final header has been written
+func (rw *responseWriter) WroteHeader() bool {
+	return rw.wroteHeader
+}
+
+// HeaderWrittenAt returns when the final header was written
+func (rw *responseWriter) HeaderWrittenAt() time.Time {
+	return rw.headerWrittenAt
+}
+
+// Hijacked reports whether the handler took over the connection
+func (rw *responseWriter) Hijacked() bool {
+	return rw.hijacked
+}
+
+// Tee sets a writer that receives a copy of the response body as it is written
+func (rw *responseWriter) Tee(w io.Writer) {
+	rw.tee = w
+}
+
+// Unwrap returns the underlying writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *responseWriter) markHeaderWritten() {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.headerWrittenAt = time.Now()
+	}
+}
+
+func (rw *responseWriter) record(data []byte) {
+	rw.bytesWritten += int64(len(data))
+	if rw.tee != nil {
+		rw.tee.Write(data)
+	}
+}
+
+// writerOnly hides the io.ReaderFrom implementation of the writer so that io.Copy falls back to Write
+type writerOnly struct {
+	io.Writer
+}
@@ -0,0 +1,247 @@
+package wrappers
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type plainWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (w *plainWriter) Header() http.Header            { return w.header }
+func (w *plainWriter) Write(data []byte) (int, error) { return w.body.Write(data) }
+func (w *plainWriter) WriteHeader(code int)           { w.status = code }
+
+type pushWriter struct {
+	*plainWriter
+	pushed []string
+}
+
+func (w *pushWriter) Flush() {}
+func (w *pushWriter) Push(target string, opts *http.PushOptions) error {
+	w.pushed = append(w.pushed, target)
+	return nil
+}
+
+type readerFromWriter struct {
+	*plainWriter
+	readFrom bool
+}
+
+func (w *readerFromWriter) ReadFrom(src io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(&w.body, src)
+}
+
+type hijackWriter struct {
+	*plainWriter
+}
+
+func (w *hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	server, client := net.Pipe()
+	client.Close()
+	return server, nil, nil
+}
+
+// TestNewResponseWriter_OptionalInterfaces checks that the wrapper implements exactly the optional interfaces supported by the underlying writer
+func TestNewResponseWriter_OptionalInterfaces(t *testing.T) {
+	cases := []struct {
+		name               string
+		writer             http.ResponseWriter
+		expectedFlusher    bool
+		expectedHijacker   bool
+		expectedReaderFrom bool
+		expectedPusher     bool
+	}{
+		{"Plain writer", &plainWriter{header: http.Header{}}, false, false, false, false},
+		{"Recorder", httptest.NewRecorder(), true, false, false, false},
+		{"Hijacker", &hijackWriter{&plainWriter{header: http.Header{}}}, false, true, false, false},
+		{"Reader from", &readerFromWriter{plainWriter: &plainWriter{header: http.Header{}}}, false, false, true, false},
+		{"HTTP/2 writer", &pushWriter{plainWriter: &plainWriter{header: http.Header{}}}, true, false, false, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rw := NewResponseWriter(tt.writer)
+
+			// Assert
+			_, isFlusher := rw.(http.Flusher)
+			_, isHijacker := rw.(http.Hijacker)
+			_, isReaderFrom := rw.(io.ReaderFrom)
+			_, isPusher := rw.(http.Pusher)
+			assert.Equal(t, tt.expectedFlusher, isFlusher)
+			assert.Equal(t, tt.expectedHijacker, isHijacker)
+			assert.Equal(t, tt.expectedReaderFrom, isReaderFrom)
+			assert.Equal(t, tt.expectedPusher, isPusher)
+			assert.Equal(t, tt.writer, rw.Unwrap())
+		})
+	}
+}
+
+// TestNewResponseWriter_HTTPServer checks that the wrapper of a real HTTP/1.1 server writer exposes its flusher, hijacker and reader from implementations
+func TestNewResponseWriter_HTTPServer(t *testing.T) {
+	// Arrange
+	var isFlusher, isHijacker, isReaderFrom, isPusher bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := NewResponseWriter(w)
+		_, isFlusher = rw.(http.Flusher)
+		_, isHijacker = rw.(http.Hijacker)
+		_, isReaderFrom = rw.(io.ReaderFrom)
+		_, isPusher = rw.(http.Pusher)
+	}))
+	defer server.Close()
+
+	// Act
+	resp, err := http.Get(server.URL)
+
+	// Assert
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.True(t, isFlusher)
+	assert.True(t, isHijacker)
+	assert.True(t, isReaderFrom)
+	assert.False(t, isPusher)
+}
+
+// TestResponseWriter_Recording checks that the status code, the bytes written and the header time are recorded
+func TestResponseWriter_Recording(t *testing.T) {
+	// Arrange
+	underlying := &plainWriter{header: http.Header{}}
+	rw := NewResponseWriter(underlying)
+
+	// Act
+	assert.Equal(t, http.StatusOK, rw.Status())
+	assert.False(t, rw.WroteHeader())
+	rw.WriteHeader(http.StatusEarlyHints)
+	assert.False(t, rw.WroteHeader())
+	rw.WriteHeader(http.StatusCreated)
+	rw.WriteHeader(http.StatusInternalServerError)
+	rw.Write([]byte("test-"))
+	rw.Write([]byte("response"))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, rw.Status())
+	assert.True(t, rw.WroteHeader())
+	assert.False(t, rw.HeaderWrittenAt().IsZero())
+	assert.Equal(t, int64(13), rw.BytesWritten())
+	assert.Equal(t, "test-response", underlying.body.String())
+}
+
+// TestResponseWriter_ImplicitHeader checks that writing the body without a header records the default status code
+func TestResponseWriter_ImplicitHeader(t *testing.T) {
+	// Arrange
+	rw := NewResponseWriter(&plainWriter{header: http.Header{}})
+
+	// Act
+	rw.Write([]byte("test-response"))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rw.Status())
+	assert.True(t, rw.WroteHeader())
+}
+
+// TestResponseWriter_InformationalHeader checks that writing the body after an informational header records the default status code
+func TestResponseWriter_InformationalHeader(t *testing.T) {
+	// Arrange
+	rw := NewResponseWriter(&plainWriter{header: http.Header{}})
+
+	// Act
+	rw.WriteHeader(http.StatusEarlyHints)
+	assert.Equal(t, http.StatusOK, rw.Status())
+	rw.Write([]byte("test-response"))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rw.Status())
+	assert.True(t, rw.WroteHeader())
+}
+
+// TestResponseWriter_Tee checks that the tee writer receives a copy of the body written directly or through ReadFrom
+func TestResponseWriter_Tee(t *testing.T) {
+	// Arrange
+	underlying := &readerFromWriter{plainWriter: &plainWriter{header: http.Header{}}}
+	rw := NewResponseWriter(underlying)
+	tee := &bytes.Buffer{}
+	rw.Tee(tee)
+
+	// Act
+	rw.Write([]byte("test-"))
+	n, err := rw.(io.ReaderFrom).ReadFrom(strings.NewReader("response"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), n)
+	assert.Equal(t, "test-response", tee.String())
+	assert.Equal(t, "test-response", underlying.body.String())
+	assert.Equal(t, int64(13), rw.BytesWritten())
+}
+
+// TestResponseWriter_ReadFrom checks that the underlying ReadFrom implementation is used when no tee writer is set
+func TestResponseWriter_ReadFrom(t *testing.T) {
+	// Arrange
+	underlying := &readerFromWriter{plainWriter: &plainWriter{header: http.Header{}}}
+	rw := NewResponseWriter(underlying)
+
+	// Act
+	rw.(io.ReaderFrom).ReadFrom(strings.NewReader("test-response"))
+
+	// Assert
+	assert.True(t, underlying.readFrom)
+	assert.Equal(t, int64(13), rw.BytesWritten())
+	assert.True(t, rw.WroteHeader())
+}
+
+// TestResponseWriter_Hijack checks that hijacking the connection before writing any header records the protocol switch
+func TestResponseWriter_Hijack(t *testing.T) {
+	// Arrange
+	rw := NewResponseWriter(&hijackWriter{&plainWriter{header: http.Header{}}})
+
+	// Act
+	conn, _, err := rw.(http.Hijacker).Hijack()
+
+	// Assert
+	assert.Nil(t, err)
+	conn.Close()
+	assert.True(t, rw.Hijacked())
+	assert.Equal(t, http.StatusSwitchingProtocols, rw.Status())
+}
+
+// TestResponseWriter_Push checks that the pushes are delegated to the underlying writer
+func TestResponseWriter_Push(t *testing.T) {
+	// Arrange
+	underlying := &pushWriter{plainWriter: &plainWriter{header: http.Header{}}}
+	rw := NewResponseWriter(underlying)
+
+	// Act
+	err := rw.(http.Pusher).Push("/static/app.js", nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/static/app.js"}, underlying.pushed)
+}
+
+// TestResponseWriter_ResponseController checks that http.ResponseController reaches the underlying writer through Unwrap
+func TestResponseWriter_ResponseController(t *testing.T) {
+	// Arrange
+	rr := httptest.NewRecorder()
+	rw := NewResponseWriter(rr)
+
+	// Act
+	err := http.NewResponseController(rw).Flush()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, rr.Flushed)
+	assert.True(t, rw.WroteHeader())
+}